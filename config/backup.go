@@ -0,0 +1,160 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	backupDir = "backups"
+
+	// backups kept of each keyb file, older ones are removed
+	keptBackups = 10
+)
+
+// Previous versions of a keyb file, saved in the state dir before each write
+type Backups struct {
+	file string
+	dir  string
+}
+
+// Backup of a keyb file and the time it was replaced
+type Backup struct {
+	Path string
+	Time time.Time
+	Size int64
+}
+
+// Load backups of keyb file from the default state directory
+func LoadBackups(keybFile string) (*Backups, error) {
+	xdgStateDir, err := getXDGStateDir()
+	if err != nil {
+		return nil, err
+	}
+
+	keybFile, err = filepath.Abs(keybFile)
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve keyb file: %w", err)
+	}
+	return NewBackups(keybFile, filepath.Join(xdgStateDir, defaultConfigDir, backupDir, pathKey(keybFile))), nil
+}
+
+func NewBackups(keybFile, dir string) *Backups {
+	return &Backups{file: keybFile, dir: dir}
+}
+
+// Backups, most recent first
+func (b *Backups) List() ([]Backup, error) {
+	entries, err := os.ReadDir(b.dir)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to list backups: %w", err)
+	}
+
+	var res []Backup
+	for _, e := range entries {
+		// named by the time in nanoseconds, with the extension of the keyb file
+		name := e.Name()
+		if i := strings.IndexByte(name, '.'); i >= 0 {
+			name = name[:i]
+		}
+		nsec, err := strconv.ParseInt(name, 10, 64)
+		if err != nil || e.IsDir() {
+			continue
+		}
+
+		info, err := e.Info()
+		if err != nil {
+			continue
+		}
+		res = append(res, Backup{
+			Path: filepath.Join(b.dir, e.Name()),
+			Time: time.Unix(0, nsec),
+			Size: info.Size(),
+		})
+	}
+
+	sort.Slice(res, func(i, j int) bool {
+		return res[i].Time.After(res[j].Time)
+	})
+	return res, nil
+}
+
+// Save the current keyb file as a backup, removing the oldest backups beyond
+// keptBackups. Nothing is saved if the keyb file does not exist
+func (b *Backups) save() error {
+	data, err := os.ReadFile(b.file)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil
+		}
+		return fmt.Errorf("failed to read keyb file: %w", err)
+	}
+
+	if err := os.MkdirAll(b.dir, 0744); err != nil {
+		return fmt.Errorf("failed to create backup dir: %w", err)
+	}
+
+	name := fmt.Sprintf("%019d%s", time.Now().UnixNano(), filepath.Ext(b.file))
+	if err := writeAtomic(filepath.Join(b.dir, name), data, 0644); err != nil {
+		return fmt.Errorf("failed to write backup: %w", err)
+	}
+
+	backups, err := b.List()
+	if err != nil {
+		return err
+	}
+	for i := keptBackups; i < len(backups); i++ {
+		if err := os.Remove(backups[i].Path); err != nil {
+			return fmt.Errorf("failed to remove backup: %w", err)
+		}
+	}
+	return nil
+}
+
+// Replace the keyb file with backup n, 1 being the most recent. The current
+// version is backed up first, so a restore can itself be restored
+func (b *Backups) Restore(n int) error {
+	unlock, err := lockKeyb(b.file)
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	backups, err := b.List()
+	if err != nil {
+		return err
+	}
+	if n < 1 || n > len(backups) {
+		return fmt.Errorf("backup %d not found", n)
+	}
+
+	data, err := os.ReadFile(backups[n-1].Path)
+	if err != nil {
+		return fmt.Errorf("failed to read backup: %w", err)
+	}
+	return b.write(data)
+}
+
+// Replace the keyb file with data after saving it as a backup
+func (b *Backups) write(data []byte) error {
+	if err := b.save(); err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(b.file), 0744); err != nil {
+		return fmt.Errorf("failed to create keyb dir: %w", err)
+	}
+	if err := writeAtomic(b.file, data, 0644); err != nil {
+		return fmt.Errorf("failed to write keyb file: %w", err)
+	}
+	return nil
+}
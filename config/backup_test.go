@@ -0,0 +1,98 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestBackups(t *testing.T) {
+	dir := t.TempDir()
+	t.Setenv("XDG_STATE_HOME", filepath.Join(dir, "state"))
+	path := filepath.Join(dir, "keyb.yml")
+
+	for i := range keptBackups + 3 {
+		if err := writeKeyb(path, fmt.Appendf(nil, "- name: v%d\n", i)); err != nil {
+			t.Fatalf("unexpected err: %v", err)
+		}
+	}
+
+	b, err := LoadBackups(path)
+	if err != nil {
+		t.Fatalf("unexpected err: %v", err)
+	}
+	list, err := b.List()
+	if err != nil {
+		t.Fatalf("unexpected err: %v", err)
+	}
+
+	t.Run("rotate", func(t *testing.T) {
+		if len(list) != keptBackups {
+			t.Fatalf("got %d backups, want %d", len(list), keptBackups)
+		}
+		// the first write had nothing to back up, so the oldest kept is v2
+		newest, _ := os.ReadFile(list[0].Path)
+		oldest, _ := os.ReadFile(list[keptBackups-1].Path)
+		if string(newest) != "- name: v11\n" || string(oldest) != "- name: v2\n" {
+			t.Errorf("got %q to %q, want v11 to v2", newest, oldest)
+		}
+	})
+
+	t.Run("restore", func(t *testing.T) {
+		if err := b.Restore(2); err != nil {
+			t.Fatalf("unexpected err: %v", err)
+		}
+		got, _ := os.ReadFile(path)
+		if want := "- name: v10\n"; string(got) != want {
+			t.Errorf("got %q, want %q", got, want)
+		}
+
+		// the replaced version is backed up
+		list, _ := b.List()
+		got, _ = os.ReadFile(list[0].Path)
+		if want := "- name: v12\n"; string(got) != want {
+			t.Errorf("got %q, want %q", got, want)
+		}
+	})
+
+	t.Run("not found", func(t *testing.T) {
+		if err := b.Restore(keptBackups + 1); err == nil {
+			t.Errorf("expected err")
+		}
+	})
+}
+
+func TestWriteAtomic(t *testing.T) {
+	dir := t.TempDir()
+	target := filepath.Join(dir, "dotfiles", "keyb.yml")
+	link := filepath.Join(dir, "keyb.yml")
+	writeFile(t, target, "old")
+	if err := os.Chmod(target, 0600); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Symlink(target, link); err != nil {
+		t.Skip("symlinks not supported")
+	}
+
+	if err := writeAtomic(link, []byte("new"), 0644); err != nil {
+		t.Fatalf("unexpected err: %v", err)
+	}
+
+	if info, err := os.Lstat(link); err != nil || info.Mode()&os.ModeSymlink == 0 {
+		t.Errorf("got %v, want symlink kept", info.Mode())
+	}
+	got, _ := os.ReadFile(target)
+	if string(got) != "new" {
+		t.Errorf("got %q, want %q", got, "new")
+	}
+	if info, _ := os.Stat(target); info.Mode().Perm() != 0600 {
+		t.Errorf("got mode %v, want %v", info.Mode().Perm(), os.FileMode(0600))
+	}
+
+	// no temp files are left behind
+	entries, _ := os.ReadDir(filepath.Dir(target))
+	if len(entries) != 1 {
+		t.Errorf("got %v, want only keyb.yml", entries)
+	}
+}
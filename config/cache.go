@@ -0,0 +1,161 @@
+package config
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/gob"
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+const cacheExt = ".gob"
+
+// Cache of parsed keyb files. Entries are keyed by path and reused while the
+// size, modification time and content hash of the file are unchanged
+type Cache struct {
+	dir string
+}
+
+type cacheEntry struct {
+	Path    string
+	Size    int64
+	ModTime int64
+	Hash    [sha256.Size]byte
+	Apps    Apps
+}
+
+// Load cache from the default cache directory
+func LoadCache() (*Cache, error) {
+	xdgCacheDir, err := getXDGCacheDir()
+	if err != nil {
+		return nil, err
+	}
+	return NewCache(filepath.Join(xdgCacheDir, defaultConfigDir)), nil
+}
+
+func NewCache(dir string) *Cache {
+	return &Cache{dir: dir}
+}
+
+// Remove all cached entries
+func (c *Cache) Clear() error {
+	entries, err := filepath.Glob(filepath.Join(c.dir, "*"+cacheExt))
+	if err != nil {
+		return fmt.Errorf("failed to list cache: %w", err)
+	}
+	for _, e := range entries {
+		if err := os.Remove(e); err != nil {
+			return fmt.Errorf("failed to clear cache: %w", err)
+		}
+	}
+	return nil
+}
+
+func (c *Cache) entryPath(path string) string {
+	return filepath.Join(c.dir, pathKey(path)+cacheExt)
+}
+
+// Cached apps of key, if the entry is present and matches key
+func (c *Cache) get(key cacheEntry) (Apps, bool) {
+	file, err := os.Open(c.entryPath(key.Path))
+	if err != nil {
+		return nil, false
+	}
+	defer file.Close()
+
+	// unreadable entries are treated as missing and overwritten
+	var e cacheEntry
+	if err := gob.NewDecoder(file).Decode(&e); err != nil {
+		return nil, false
+	}
+	if e.Path != key.Path || e.Size != key.Size || e.ModTime != key.ModTime || e.Hash != key.Hash {
+		return nil, false
+	}
+	return e.Apps, true
+}
+
+func (c *Cache) put(key cacheEntry, apps Apps) error {
+	key.Apps = apps
+
+	var buf bytes.Buffer
+	if err := gob.NewEncoder(&buf).Encode(key); err != nil {
+		return fmt.Errorf("failed to encode cache entry: %w", err)
+	}
+
+	if err := os.MkdirAll(c.dir, 0744); err != nil {
+		return fmt.Errorf("failed to create cache dir: %w", err)
+	}
+	if err := writeAtomic(c.entryPath(key.Path), buf.Bytes(), 0644); err != nil {
+		return fmt.Errorf("failed to write cache entry: %w", err)
+	}
+	return nil
+}
+
+// Read and parse keyb file at path, reusing the cached result if the file is
+// unchanged. A nil cache parses the file every time
+func readKeyb(path string, cache *Cache) (Apps, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read keyb file: %w", err)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read keyb file: %w", err)
+	}
+
+	key := cacheEntry{
+		Path:    path,
+		Size:    info.Size(),
+		ModTime: info.ModTime().UnixNano(),
+		Hash:    sha256.Sum256(data),
+	}
+	if cache != nil {
+		if apps, ok := cache.get(key); ok {
+			return apps, nil
+		}
+	}
+
+	apps, err := parseKeyb(path, data)
+	if err != nil {
+		return nil, err
+	}
+
+	if cache != nil {
+		// cache is best effort and must not prevent reading keyb files
+		_ = cache.put(key, apps)
+	}
+	return apps, nil
+}
+
+// Read all keyb files in dir and its subdirectories, in lexical order
+func readKeybDir(dir string, cache *Cache) (Apps, error) {
+	var res Apps
+	err := filepath.WalkDir(dir, func(path string, d os.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() || !isKeybFile(path) {
+			return nil
+		}
+
+		apps, err := readKeyb(path, cache)
+		if err != nil {
+			return err
+		}
+		res = append(res, apps...)
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to read keyb dir: %w", err)
+	}
+	return res, nil
+}
+
+func isKeybFile(path string) bool {
+	switch filepath.Ext(path) {
+	case ".json", ".yaml", ".yml":
+		return true
+	}
+	return false
+}
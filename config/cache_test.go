@@ -0,0 +1,101 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestCache(t *testing.T) {
+	dir := t.TempDir()
+	cache := NewCache(filepath.Join(dir, "cache"))
+	path := filepath.Join(dir, "keyb.yml")
+	mtime := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	write := func(name string) {
+		t.Helper()
+		data := []byte("- name: app\n  keybinds:\n    - name: " + name + "\n      key: a\n")
+		if err := os.WriteFile(path, data, 0644); err != nil {
+			t.Fatalf("unexpected err: %v", err)
+		}
+		if err := os.Chtimes(path, mtime, mtime); err != nil {
+			t.Fatalf("unexpected err: %v", err)
+		}
+	}
+	read := func() string {
+		t.Helper()
+		apps, err := readKeyb(path, cache)
+		if err != nil {
+			t.Fatalf("unexpected err: %v", err)
+		}
+		return apps[0].Keybinds[0].Name
+	}
+
+	write("foo")
+	if got := read(); got != "foo" {
+		t.Errorf("got %v, want %v", got, "foo")
+	}
+
+	t.Run("reuse entry", func(t *testing.T) {
+		entries, _ := filepath.Glob(filepath.Join(dir, "cache", "*.gob"))
+		if len(entries) != 1 {
+			t.Fatalf("got %d entries, want 1", len(entries))
+		}
+		if got := read(); got != "foo" {
+			t.Errorf("got %v, want %v", got, "foo")
+		}
+	})
+
+	t.Run("stale entry", func(t *testing.T) {
+		// same size and modification time, so only the hash differs
+		write("bar")
+		if got := read(); got != "bar" {
+			t.Errorf("got %v, want %v", got, "bar")
+		}
+	})
+
+	t.Run("clear", func(t *testing.T) {
+		if err := cache.Clear(); err != nil {
+			t.Fatalf("unexpected err: %v", err)
+		}
+		entries, _ := filepath.Glob(filepath.Join(dir, "cache", "*.gob"))
+		if len(entries) != 0 {
+			t.Errorf("got %d entries, want 0", len(entries))
+		}
+	})
+}
+
+func TestKeybDir(t *testing.T) {
+	dir := t.TempDir()
+	files := map[string]string{
+		"b.yml":          "- name: b\n  keybinds: []\n",
+		"a.json":         `[{"name": "a", "keybinds": []}]`,
+		"sub/c.yaml":     "- name: c\n  keybinds: []\n",
+		"sub/README.txt": "not a keyb file",
+	}
+	for name, data := range files {
+		path := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatalf("unexpected err: %v", err)
+		}
+		if err := os.WriteFile(path, []byte(data), 0644); err != nil {
+			t.Fatalf("unexpected err: %v", err)
+		}
+	}
+
+	apps, err := unmarshalKeyb(dir, "", NewCache(t.TempDir()))
+	if err != nil {
+		t.Fatalf("unexpected err: %v", err)
+	}
+
+	var got []string
+	for _, app := range apps {
+		got = append(got, app.Name)
+	}
+	want := []string{"a", "b", "c"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got %v, want %v", got, want)
+	}
+}
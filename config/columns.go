@@ -0,0 +1,48 @@
+package config
+
+import (
+	"fmt"
+	"slices"
+)
+
+// Column of the key bindings list
+type Column struct {
+	Field    string `yaml:"field" json:"field"`
+	Align    string `yaml:"align,omitempty" json:"align,omitempty"`
+	MaxWidth int    `yaml:"max_width,omitempty" json:"max_width,omitempty"`
+	Fg       string `yaml:"fg,omitempty" json:"fg,omitempty"`
+	Bg       string `yaml:"bg,omitempty" json:"bg,omitempty"`
+	Bold     bool   `yaml:"bold,omitempty" json:"bold,omitempty"`
+	Italic   bool   `yaml:"italic,omitempty" json:"italic,omitempty"`
+}
+
+// Fields of a key binding that can be shown as columns
+var ColumnFields = []string{"name", "key", "prefix", "mode", "tags", "notes", "app"}
+
+var columnAligns = []string{"", "left", "right", "center"}
+
+// Columns to show. Defaults to name and key, or key and name if reversed
+func (c *Config) RowColumns() []Column {
+	if len(c.Columns) > 0 {
+		return c.Columns
+	}
+	if c.Reverse {
+		return []Column{{Field: "key"}, {Field: "name"}}
+	}
+	return []Column{{Field: "name"}, {Field: "key"}}
+}
+
+func validateColumns(columns []Column) error {
+	for _, col := range columns {
+		if !slices.Contains(ColumnFields, col.Field) {
+			return fmt.Errorf("invalid column field %q", col.Field)
+		}
+		if !slices.Contains(columnAligns, col.Align) {
+			return fmt.Errorf("invalid column align %q", col.Align)
+		}
+		if col.MaxWidth < 0 {
+			return fmt.Errorf("invalid column max_width %d", col.MaxWidth)
+		}
+	}
+	return nil
+}
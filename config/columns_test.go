@@ -0,0 +1,54 @@
+package config
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestRowColumns(t *testing.T) {
+	tests := []struct {
+		name   string
+		config Config
+		want   []Column
+	}{
+		{"default", Config{}, []Column{{Field: "name"}, {Field: "key"}}},
+		{"reverse", Config{Settings: Settings{Reverse: true}}, []Column{{Field: "key"}, {Field: "name"}}},
+		{
+			"columns",
+			Config{Settings: Settings{Reverse: true, Columns: []Column{{Field: "mode"}, {Field: "name"}}}},
+			[]Column{{Field: "mode"}, {Field: "name"}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := tt.config.RowColumns()
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("got %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestValidateColumns(t *testing.T) {
+	tests := []struct {
+		name    string
+		columns []Column
+		wantErr bool
+	}{
+		{"empty", nil, false},
+		{"valid", []Column{{Field: "key", Align: "right", MaxWidth: 10}, {Field: "tags"}}, false},
+		{"invalid field", []Column{{Field: "foo"}}, true},
+		{"invalid align", []Column{{Field: "name", Align: "top"}}, true},
+		{"negative max width", []Column{{Field: "name", MaxWidth: -1}}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := validateColumns(tt.columns)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("got err %v, want err %v", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -1,11 +1,15 @@
 package config
 
 import (
+	"bytes"
 	"encoding/json"
 	"errors"
 	"fmt"
+	"maps"
 	"os"
 	"path/filepath"
+	"slices"
+	"strings"
 
 	"gopkg.in/yaml.v2"
 )
@@ -20,6 +24,17 @@ type Config struct {
 	Settings `yaml:"settings" json:"settings"`
 	Color    `yaml:"color" json:"color"`
 	Keys     `yaml:"keys" json:"keys"`
+
+	// named search queries
+	Saved map[string]string `yaml:"saved,omitempty" json:"saved,omitempty"`
+
+	theme *Theme
+	// keyb file that was read
+	keybFile string
+	// layer each setting came from
+	origins Origins
+	// nothing is written to disk
+	readOnly bool
 }
 
 type Settings struct {
@@ -29,7 +44,15 @@ type Settings struct {
 	Mouse          bool
 	SearchMode     bool `yaml:"search_mode" json:"search_mode"`
 	SortKeys       bool `yaml:"sort_keys" json:"sort_keys"`
+	Frecency       bool
+	Collapsed      bool
+	SkipHeadings   bool `yaml:"skip_headings" json:"skip_headings"`
+	KeyTimeout     int  `yaml:"key_timeout" json:"key_timeout"`
 	Title          string
+	TitleLocation  string `yaml:"title_location" json:"title_location"`
+	StatusLeft     string `yaml:"status_left" json:"status_left"`
+	StatusCenter   string `yaml:"status_center" json:"status_center"`
+	StatusRight    string `yaml:"status_right" json:"status_right"`
 	Prompt         string
 	PromptLocation string `yaml:"prompt_location" json:"prompt_location"`
 	Placeholder    string
@@ -38,6 +61,17 @@ type Settings struct {
 	Margin         int
 	Padding        int
 	BorderStyle    string `yaml:"border" json:"border"`
+	Theme          string
+	HistorySize    int  `yaml:"history_size" json:"history_size"`
+	ShowHelp       bool `yaml:"show_help" json:"show_help"`
+	Scrollbar      bool
+	HeadingClick   string `yaml:"heading_click" json:"heading_click"`
+	Height         string
+	Layout         string
+	GridColumns    int      `yaml:"grid_columns" json:"grid_columns"`
+	Columns        []Column `yaml:"columns,omitempty" json:"columns,omitempty"`
+	Wrap           bool
+	TrustedDirs    []string `yaml:"trusted_dirs,omitempty" json:"trusted_dirs,omitempty"`
 }
 
 type Color struct {
@@ -51,6 +85,16 @@ type Color struct {
 	PlaceholderFg string `yaml:"placeholder_fg" json:"placeholder_fg"`
 	PlaceholderBg string `yaml:"placeholder_bg" json:"placeholder_bg"`
 	BorderColor   string `yaml:"border_color" json:"border_color"`
+	HeadingFg     string `yaml:"heading_fg" json:"heading_fg"`
+	HeadingBg     string `yaml:"heading_bg" json:"heading_bg"`
+	RowFg         string `yaml:"row_fg" json:"row_fg"`
+	RowBg         string `yaml:"row_bg" json:"row_bg"`
+	KeyFg         string `yaml:"key_fg" json:"key_fg"`
+	KeyBg         string `yaml:"key_bg" json:"key_bg"`
+	PrefixFg      string `yaml:"prefix_fg" json:"prefix_fg"`
+	PrefixBg      string `yaml:"prefix_bg" json:"prefix_bg"`
+	TitleFg       string `yaml:"title_fg" json:"title_fg"`
+	TitleBg       string `yaml:"title_bg" json:"title_bg"`
 }
 
 type Keys struct {
@@ -68,9 +112,29 @@ type Keys struct {
 	GoToTop                  string `yaml:"top" json:"top"`
 	GoToMiddle               string `yaml:"middle" json:"middle"`
 	GoToBottom               string `yaml:"bottom" json:"bottom"`
+	CenterCursor             string `yaml:"center_cursor" json:"center_cursor"`
+	NextHeading              string `yaml:"next_heading" json:"next_heading"`
+	PrevHeading              string `yaml:"prev_heading" json:"prev_heading"`
+	AppPicker                string `yaml:"app_picker" json:"app_picker"`
+	Left                     string
+	Right                    string
 	Search                   string
 	ClearSearch              string `yaml:"clear_search" json:"clear_search"`
 	Normal                   string
+	Accept                   string
+	HistoryPrev              string `yaml:"history_prev" json:"history_prev"`
+	HistoryNext              string `yaml:"history_next" json:"history_next"`
+	HistorySearch            string `yaml:"history_search" json:"history_search"`
+	SavedQueries             string `yaml:"saved_queries" json:"saved_queries"`
+	Pin                      string
+	Copy                     string
+	Delete                   string
+	Undo                     string
+	Redo                     string
+	Fold                     string
+	CollapseAll              string `yaml:"collapse_all" json:"collapse_all"`
+	ExpandAll                string `yaml:"expand_all" json:"expand_all"`
+	Help                     string
 	CursorWordForward        string `yaml:"cursor_word_forward" json:"cursor_word_forward"`
 	CursorWordBackward       string `yaml:"cursor_word_backward" json:"cursor_word_backward"`
 	CursorDeleteWordBackward string `yaml:"cursor_delete_word_backward" json:"cursor_delete_word_backward"`
@@ -89,7 +153,15 @@ var DefaultConfig = &Config{
 		Mouse:          true,
 		SearchMode:     false,
 		SortKeys:       false,
+		Frecency:       false,
+		Collapsed:      false,
+		SkipHeadings:   false,
+		KeyTimeout:     1000,
 		Title:          "",
+		TitleLocation:  "header",
+		StatusLeft:     "{filtered}/{total} {heading}",
+		StatusCenter:   "",
+		StatusRight:    "{status}",
 		Prompt:         "keys > ",
 		PromptLocation: "top",
 		Placeholder:    "...",
@@ -98,9 +170,12 @@ var DefaultConfig = &Config{
 		Margin:         0,
 		Padding:        1,
 		BorderStyle:    "hidden",
-	},
-	Color: Color{
-		FilterFg: "#FFA066",
+		Theme:          defaultTheme,
+		HistorySize:    100,
+		ShowHelp:       true,
+		Scrollbar:      true,
+		HeadingClick:   "fold",
+		Layout:         "list",
 	},
 	Keys: Keys{
 		Quit:                     "q, ctrl+c",
@@ -117,9 +192,29 @@ var DefaultConfig = &Config{
 		GoToTop:                  "H",
 		GoToMiddle:               "M",
 		GoToBottom:               "L",
+		CenterCursor:             "zz",
+		NextHeading:              "]]",
+		PrevHeading:              "[[",
+		AppPicker:                "o",
+		Left:                     "h",
+		Right:                    "l",
 		Search:                   "/",
 		ClearSearch:              "alt+d",
 		Normal:                   "esc",
+		Accept:                   "enter",
+		HistoryPrev:              "up",
+		HistoryNext:              "down",
+		HistorySearch:            "ctrl+r",
+		SavedQueries:             "s",
+		Pin:                      "p",
+		Copy:                     "y",
+		Delete:                   "dd",
+		Undo:                     "u",
+		Redo:                     "ctrl+r",
+		Fold:                     "tab",
+		CollapseAll:              "-",
+		ExpandAll:                "+, =",
+		Help:                     "?",
 		CursorWordForward:        "alt+right, alt+f",
 		CursorWordBackward:       "alt+left, alt+b",
 		CursorDeleteWordBackward: "alt+backspace",
@@ -132,35 +227,88 @@ var DefaultConfig = &Config{
 	},
 }
 
-// Read configuration and keyb file from flags, default path.
-func Parse(flagCPath, flagKPath string) (Apps, *Config, error) {
+// Options given on the command line
+type Flags struct {
+	// config file replacing the user config
+	Config string
+	// profile merged over the config
+	Profile string
+	// settings keyed by name, such as settings.keyb_path
+	Values map[string]string
+	// write nothing to disk
+	ReadOnly bool
+}
+
+// Read configuration and keyb file from config layers
+func Parse(flags Flags) (Apps, *Config, error) {
 	xdgConfigDir, err := getXDGConfigDir()
 	if err != nil {
 		return nil, nil, err
 	}
 
 	basePath := filepath.Join(xdgConfigDir, defaultConfigDir)
-	err = os.MkdirAll(basePath, 0744)
+	config, origins, err := resolveConfig(flags, basePath)
 	if err != nil {
-		return nil, nil, fmt.Errorf("failed to create config dir: %w", err)
+		return nil, nil, err
 	}
+	config.origins = origins
 
-	config, err := UnmarshalConfig(flagCPath, basePath)
-	if err != nil {
+	if err := validateColumns(config.Columns); err != nil {
 		return nil, nil, err
 	}
 
-	if flagKPath == "" {
-		flagKPath = config.KeybPath
+	config.keybFile = config.KeybPath
+	if config.keybFile == "" {
+		config.keybFile = filepath.Join(basePath, defaultKeybFile)
 	}
+	config.keybFile = ExpandPath(config.keybFile, "")
 
-	keys, err := UnmarshalKeyb(flagKPath, basePath)
+	// read-only homes and managed dotfiles cannot be written to
+	config.readOnly = flags.ReadOnly || !writable(config.keybFile)
+
+	keys, err := unmarshalKeyb(config.keybFile, basePath, config.cache())
+	if err != nil {
+		return nil, nil, err
+	}
+
+	theme, err := LoadTheme(config.Theme, basePath)
 	if err != nil {
 		return nil, nil, err
 	}
+	config.theme = &theme
+
 	return keys, config, nil
 }
 
+// Path of the keyb file that was read
+func (c *Config) KeybFile() string {
+	return c.keybFile
+}
+
+// Whether writing to disk is disabled, by flag or because the keyb file is
+// not writable
+func (c *Config) ReadOnly() bool {
+	return c.readOnly
+}
+
+// Cache of parsed keyb files, nil if unavailable or in read-only mode
+func (c *Config) cache() *Cache {
+	if c.readOnly {
+		return nil
+	}
+	cache, _ := LoadCache()
+	return cache
+}
+
+// Colors of the selected theme with colors set in the config applied over it
+func (c *Config) Palette() Theme {
+	theme := builtinThemes[defaultTheme]
+	if c.theme != nil {
+		theme = *c.theme
+	}
+	return theme.With(c.Color)
+}
+
 // Read config file and merge with default config
 func UnmarshalConfig(configFile, basePath string) (*Config, error) {
 
@@ -169,69 +317,76 @@ func UnmarshalConfig(configFile, basePath string) (*Config, error) {
 		configFile = filepath.Join(basePath, defaultConfigFile)
 	}
 
-	res := newDefaultConfig(basePath)
-	configFile = os.ExpandEnv(configFile)
-
-	file, err := os.ReadFile(configFile)
+	base, err := defaultLayer(basePath)
 	if err != nil {
-		if errors.Is(err, os.ErrNotExist) {
-			return res, nil
-		} else {
-			return nil, fmt.Errorf("failed to read config file \"%s\": %w", configFile, err)
-		}
+		return nil, err
 	}
+	layers := []layer{base}
 
-	switch filepath.Ext(configFile) {
-	case ".json":
-		if err = json.Unmarshal(file, &res); err != nil {
-			return nil, fmt.Errorf("failed to unmarshal config file \"%s\": %w", configFile, err)
-		}
-	case ".yaml", ".yml":
-		if err = yaml.Unmarshal(file, &res); err != nil {
-			return nil, fmt.Errorf("failed to unmarshal config file \"%s\": %w", configFile, err)
-		}
+	l, ok, err := fileLayer(ExpandPath(configFile, ""))
+	if err != nil {
+		return nil, err
+	}
+	if ok {
+		layers = append(layers, l)
 	}
 
-	return res, nil
+	res, _, err := mergeLayers(layers)
+	return res, err
 }
 
 func newDefaultConfig(basePath string) *Config {
-	res := DefaultConfig
+	res := DefaultConfig.Copy()
 	res.KeybPath = filepath.Join(basePath, defaultKeybFile)
 	return res
 }
 
-// Read keyb file or create default keyb file not exist
+// Deep copy of config that shares no slices, maps or pointers with c
+func (c *Config) Copy() *Config {
+	res := *c
+	res.Columns = slices.Clone(c.Columns)
+	res.TrustedDirs = slices.Clone(c.TrustedDirs)
+	res.Saved = maps.Clone(c.Saved)
+	res.origins = maps.Clone(c.origins)
+	if c.theme != nil {
+		theme := *c.theme
+		res.theme = &theme
+	}
+	return &res
+}
+
+// Read keyb file. A missing file results in placeholder key bindings, which
+// are not written to disk
 func UnmarshalKeyb(keybFile, basePath string) (Apps, error) {
+	return unmarshalKeyb(keybFile, basePath, nil)
+}
+
+// Read keyb file or directory of keyb files through cache
+func unmarshalKeyb(keybFile, basePath string, cache *Cache) (Apps, error) {
 	if keybFile == "" {
 		keybFile = filepath.Join(basePath, defaultKeybFile)
 	}
 
-	keybFile = os.ExpandEnv(keybFile)
-	file, err := os.ReadFile(keybFile)
+	keybFile = ExpandPath(keybFile, "")
+	info, err := os.Stat(keybFile)
 	if err != nil {
 		if errors.Is(err, os.ErrNotExist) {
-
-			k := newDefaultKeyb(keybFile)
-			data, err := yaml.Marshal(k)
-			if err != nil {
-				return nil, fmt.Errorf("failed to generate default keyb: %w", err)
-			}
-
-			if err := os.WriteFile(keybFile, data, 0644); err != nil {
-				return nil, fmt.Errorf("failed to create keyb file: %w", err)
-			}
-			return k, nil
-
-		} else {
-			return nil, fmt.Errorf("failed to read keyb file: %w", err)
+			return newDefaultKeyb(keybFile), nil
 		}
+		return nil, fmt.Errorf("failed to read keyb file: %w", err)
 	}
 
+	if info.IsDir() {
+		return readKeybDir(keybFile, cache)
+	}
+	return readKeyb(keybFile, cache)
+}
+
+func parseKeyb(keybFile string, file []byte) (Apps, error) {
 	var b Apps
 	switch filepath.Ext(keybFile) {
 	case ".json":
-		if err = json.Unmarshal(file, &b); err != nil {
+		if err := json.Unmarshal(file, &b); err != nil {
 			return nil, fmt.Errorf("failed to unmarshal keyb file: %w", err)
 		}
 	case ".yaml", ".yml":
@@ -242,6 +397,76 @@ func UnmarshalKeyb(keybFile, basePath string) (Apps, error) {
 	return b, nil
 }
 
+// Create the config dir with the default config and keyb files. Existing
+// files are kept. Returns the files created
+func Init() ([]string, error) {
+	xdgConfigDir, err := getXDGConfigDir()
+	if err != nil {
+		return nil, err
+	}
+
+	basePath := filepath.Join(xdgConfigDir, defaultConfigDir)
+	if err := os.MkdirAll(basePath, 0744); err != nil {
+		return nil, fmt.Errorf("failed to create config dir: %w", err)
+	}
+
+	configFile := filepath.Join(basePath, defaultConfigFile)
+	keybFile := filepath.Join(basePath, defaultKeybFile)
+
+	config, err := configTemplate()
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate \"%s\": %w", configFile, err)
+	}
+	keyb, err := yaml.Marshal(newDefaultKeyb(keybFile))
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate \"%s\": %w", keybFile, err)
+	}
+
+	files := []struct {
+		path string
+		data []byte
+	}{
+		{configFile, config},
+		{keybFile, keyb},
+	}
+
+	var created []string
+	for _, f := range files {
+		if _, err := os.Stat(f.path); err == nil {
+			continue
+		}
+		if err := writeAtomic(f.path, f.data, 0644); err != nil {
+			return nil, fmt.Errorf("failed to create \"%s\": %w", f.path, err)
+		}
+		created = append(created, f.path)
+	}
+	return created, nil
+}
+
+// Default config with all settings commented out, so that defaults of later
+// versions apply to settings that were never changed
+func configTemplate() ([]byte, error) {
+	c := DefaultConfig.Copy()
+	// empty keyb_path reads the keyb file next to the config file
+	c.KeybPath = ""
+
+	data, err := yaml.Marshal(c)
+	if err != nil {
+		return nil, err
+	}
+
+	var b bytes.Buffer
+	b.WriteString("# Uncomment settings to change them from their defaults\n")
+	for _, line := range strings.SplitAfter(string(data), "\n") {
+		// keep sections so that their settings can be uncommented alone
+		if line != "" && strings.HasPrefix(line, " ") {
+			line = "# " + line
+		}
+		b.WriteString(line)
+	}
+	return b.Bytes(), nil
+}
+
 func newDefaultKeyb(path string) Apps {
 	return Apps{{
 		Name: "example",
@@ -265,3 +490,60 @@ func getXDGConfigDir() (string, error) {
 	}
 	return path, nil
 }
+
+// Nearest existing path of path and its parents
+func existing(path string) string {
+	for {
+		if _, err := os.Stat(path); err == nil {
+			return path
+		}
+
+		parent := filepath.Dir(path)
+		if parent == path {
+			return path
+		}
+		path = parent
+	}
+}
+
+// get user XDG_CACHE_HOME directory
+func getXDGCacheDir() (string, error) {
+	val, ok := os.LookupEnv("XDG_CACHE_HOME")
+	if ok {
+		return val, nil
+	}
+
+	path, err := os.UserCacheDir()
+	if err != nil {
+		return "", fmt.Errorf("user cache directory not found: %w", err)
+	}
+	return path, nil
+}
+
+// get user XDG_STATE_HOME directory
+func getXDGStateDir() (string, error) {
+	val, ok := os.LookupEnv("XDG_STATE_HOME")
+	if ok {
+		return val, nil
+	}
+
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", fmt.Errorf("user home directory not found: %w", err)
+	}
+	return filepath.Join(home, ".local", "state"), nil
+}
+
+// get user XDG_DATA_HOME directory
+func getXDGDataDir() (string, error) {
+	val, ok := os.LookupEnv("XDG_DATA_HOME")
+	if ok {
+		return val, nil
+	}
+
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", fmt.Errorf("user home directory not found: %w", err)
+	}
+	return filepath.Join(home, ".local", "share"), nil
+}
@@ -4,21 +4,31 @@ import (
 	"os"
 	"path/filepath"
 	"reflect"
+	"strings"
 	"testing"
 )
 
 const testBasePath = "../testdata"
 
 func TestUnmarshalConfig(t *testing.T) {
+	// keyb_path is relative to the config file
+	testdata, err := filepath.Abs(testBasePath)
+	if err != nil {
+		t.Fatalf("unexpected err: %v", err)
+	}
+
 	testConfig := &Config{
 		Settings: Settings{
-			KeybPath:       "./custom.yml",
+			KeybPath:       filepath.Join(testdata, "custom.yml"),
 			Debug:          true,
 			Reverse:        true,
 			Mouse:          false,
 			SearchMode:     false,
 			SortKeys:       true,
 			Title:          "",
+			TitleLocation:  "header",
+			StatusLeft:     "{filtered}/{total} {heading}",
+			StatusRight:    "{status}",
 			Prompt:         "keys > ",
 			PromptLocation: "bottom",
 			Placeholder:    "...",
@@ -27,6 +37,13 @@ func TestUnmarshalConfig(t *testing.T) {
 			Margin:         1,
 			Padding:        1,
 			BorderStyle:    "normal",
+			Theme:          "default",
+			KeyTimeout:     1000,
+			HistorySize:    100,
+			ShowHelp:       true,
+			Scrollbar:      true,
+			HeadingClick:   "fold",
+			Layout:         "list",
 		},
 		Color: Color{
 			FilterFg: "#FFA066",
@@ -46,9 +63,29 @@ func TestUnmarshalConfig(t *testing.T) {
 			GoToTop:                  "H",
 			GoToMiddle:               "M",
 			GoToBottom:               "L",
+			CenterCursor:             "zz",
+			NextHeading:              "]]",
+			PrevHeading:              "[[",
+			AppPicker:                "o",
+			Left:                     "h",
+			Right:                    "l",
 			Search:                   "/",
 			ClearSearch:              "alt+d",
 			Normal:                   "esc",
+			Accept:                   "enter",
+			HistoryPrev:              "up",
+			HistoryNext:              "down",
+			HistorySearch:            "ctrl+r",
+			SavedQueries:             "s",
+			Pin:                      "p",
+			Copy:                     "y",
+			Delete:                   "dd",
+			Undo:                     "u",
+			Redo:                     "ctrl+r",
+			Fold:                     "tab",
+			CollapseAll:              "-",
+			ExpandAll:                "+, =",
+			Help:                     "?",
 			CursorWordForward:        "alt+right, alt+f",
 			CursorWordBackward:       "alt+left, alt+b",
 			CursorDeleteWordBackward: "alt+backspace",
@@ -99,6 +136,34 @@ func TestUnmarshalConfig(t *testing.T) {
 	})
 }
 
+func TestDefaultConfig(t *testing.T) {
+	want := DefaultConfig.Copy()
+
+	a := newDefaultConfig("a")
+	b := newDefaultConfig("b")
+	a.Padding = 5
+	a.Saved = map[string]string{"foo": "bar"}
+	a.Columns = append(a.Columns, Column{Field: "name"})
+
+	if b.KeybPath != filepath.Join("b", defaultKeybFile) || b.Padding != want.Padding {
+		t.Errorf("got %v, want configs to be independent", b)
+	}
+	if !reflect.DeepEqual(DefaultConfig, want) {
+		t.Errorf("got %v, want %v", DefaultConfig, want)
+	}
+
+	t.Run("copy", func(t *testing.T) {
+		c := &Config{Settings: Settings{TrustedDirs: []string{"/a"}}, Saved: map[string]string{"a": "b"}}
+		got := c.Copy()
+		got.TrustedDirs[0] = "/b"
+		got.Saved["a"] = "c"
+
+		if c.TrustedDirs[0] != "/a" || c.Saved["a"] != "b" {
+			t.Errorf("got %v, want copy to not share state", c)
+		}
+	})
+}
+
 func TestUnmarshalKeyb(t *testing.T) {
 	apps := Apps{{
 		Name: "test",
@@ -131,17 +196,18 @@ func TestUnmarshalKeyb(t *testing.T) {
 	}
 
 	t.Run("file absent", func(t *testing.T) {
-		_, err := UnmarshalKeyb(filepath.Join(testBasePath, "temp.yml"), testBasePath)
+		path := filepath.Join(testBasePath, "temp.yml")
+		got, err := UnmarshalKeyb(path, testBasePath)
 		if err != nil {
 			t.Fatalf("unexpected err: %v", err)
 		}
 
-		t.Cleanup(func() {
-			err := os.Remove(filepath.Join(testBasePath, "temp.yml"))
-			if err != nil {
-				t.Fatal(err)
-			}
-		})
+		if want := newDefaultKeyb(path); !reflect.DeepEqual(got, want) {
+			t.Errorf("got %v, want %v", got, want)
+		}
+		if _, err := os.Stat(path); !os.IsNotExist(err) {
+			t.Errorf("got %v, want keyb file not created", err)
+		}
 	})
 
 	t.Run("empty filepath", func(t *testing.T) {
@@ -150,11 +216,94 @@ func TestUnmarshalKeyb(t *testing.T) {
 			t.Fatalf("unexpected err: %v", err)
 		}
 
-		t.Cleanup(func() {
-			err := os.Remove(filepath.Join(testBasePath, defaultKeybFile))
-			if err != nil {
-				t.Fatal(err)
-			}
-		})
+		if _, err := os.Stat(filepath.Join(testBasePath, defaultKeybFile)); !os.IsNotExist(err) {
+			t.Errorf("got %v, want keyb file not created", err)
+		}
+	})
+}
+
+func TestInit(t *testing.T) {
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+
+	created, err := Init()
+	if err != nil {
+		t.Fatalf("unexpected err: %v", err)
+	}
+	if len(created) != 2 {
+		t.Fatalf("got %v, want config and keyb files", created)
+	}
+
+	basePath := filepath.Dir(created[0])
+	config, err := UnmarshalConfig(created[0], basePath)
+	if err != nil {
+		t.Fatalf("unexpected err: %v", err)
+	}
+	if want := newDefaultConfig(basePath); !reflect.DeepEqual(config, want) {
+		t.Errorf("got %v, want %v", config, want)
+	}
+
+	// settings are left at their defaults
+	data, err := os.ReadFile(created[0])
+	if err != nil {
+		t.Fatalf("unexpected err: %v", err)
+	}
+	for _, line := range strings.Split(string(data), "\n") {
+		if strings.HasPrefix(line, " ") || strings.Contains(line, basePath) {
+			t.Errorf("got %q, want commented setting", line)
+		}
+	}
+
+	// existing files are kept
+	created, err = Init()
+	if err != nil {
+		t.Fatalf("unexpected err: %v", err)
+	}
+	if len(created) != 0 {
+		t.Errorf("got %v, want none created", created)
+	}
+}
+
+func TestReadOnly(t *testing.T) {
+	dir := t.TempDir()
+	t.Setenv("XDG_CONFIG_HOME", filepath.Join(dir, "config"))
+	t.Setenv("XDG_CACHE_HOME", filepath.Join(dir, "cache"))
+	t.Setenv("XDG_CONFIG_DIRS", filepath.Join(dir, "etc"))
+	t.Chdir(dir)
+
+	t.Run("no side effects", func(t *testing.T) {
+		keys, c, err := Parse(Flags{ReadOnly: true})
+		if err != nil {
+			t.Fatalf("unexpected err: %v", err)
+		}
+		if !c.ReadOnly() {
+			t.Errorf("got read-write, want read-only")
+		}
+		if want := newDefaultKeyb(c.KeybFile()); !reflect.DeepEqual(keys, want) {
+			t.Errorf("got %v, want %v", keys, want)
+		}
+
+		entries, _ := os.ReadDir(dir)
+		if len(entries) != 0 {
+			t.Errorf("got %v, want nothing written", entries)
+		}
+	})
+
+	t.Run("unwritable keyb file", func(t *testing.T) {
+		path := filepath.Join(dir, "keyb.yml")
+		writeFile(t, path, "- name: app\n  keybinds: []\n")
+		if err := os.Chmod(path, 0444); err != nil {
+			t.Fatal(err)
+		}
+		if writable(path) {
+			t.Skip("file is writable regardless of permissions")
+		}
+
+		_, c, err := Parse(Flags{Values: map[string]string{"settings.keyb_path": path}})
+		if err != nil {
+			t.Fatalf("unexpected err: %v", err)
+		}
+		if !c.ReadOnly() {
+			t.Errorf("got read-write, want read-only")
+		}
 	})
 }
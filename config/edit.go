@@ -0,0 +1,408 @@
+package config
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"slices"
+
+	"gopkg.in/yaml.v2"
+)
+
+const (
+	undoDir = "undo"
+
+	// changes kept in the undo log of each keyb file
+	keptOps = 100
+)
+
+var errChanged = errors.New("keyb file changed")
+
+// Change to a key binding of an app. Before and After are the key binding
+// before and after the change, Before is nil if it was added and After is nil
+// if it was removed
+type Op struct {
+	App    string `json:"app"`
+	Prefix string `json:"prefix,omitempty"`
+	// position of the app and of the key binding in the app
+	AppIndex int      `json:"app_index"`
+	Index    int      `json:"index"`
+	Before   *KeyBind `json:"before,omitempty"`
+	After    *KeyBind `json:"after,omitempty"`
+}
+
+// Change that reverts op
+func (op Op) Inverse() Op {
+	op.Before, op.After = op.After, op.Before
+	return op
+}
+
+func (op Op) String() string {
+	switch {
+	case op.Before == nil:
+		return fmt.Sprintf("add \"%s\" to \"%s\"", op.After.Name, op.App)
+	case op.After == nil:
+		return fmt.Sprintf("remove \"%s\" from \"%s\"", op.Before.Name, op.App)
+	default:
+		return fmt.Sprintf("edit \"%s\" in \"%s\"", op.Before.Name, op.App)
+	}
+}
+
+// Index of the first app named name, -1 if there is none
+func (apps Apps) index(name string) int {
+	return slices.IndexFunc(apps, func(a *App) bool {
+		return a.Name == name
+	})
+}
+
+// Change adding kb to the end of app, which is created if missing
+func (apps Apps) addOp(app string, kb KeyBind) Op {
+	op := Op{App: app, AppIndex: len(apps), After: &kb}
+	if i := apps.index(app); i >= 0 {
+		op.AppIndex = i
+		op.Index = len(apps[i].Keybinds)
+		op.Prefix = apps[i].Prefix
+	}
+	return op
+}
+
+// Change removing the first key binding of app with name, and key if not
+// empty
+func (apps Apps) removeOp(app, name, key string) (Op, error) {
+	i := apps.index(app)
+	if i < 0 {
+		return Op{}, fmt.Errorf("app \"%s\" not found", app)
+	}
+
+	j := slices.IndexFunc(apps[i].Keybinds, func(kb KeyBind) bool {
+		return kb.Name == name && (key == "" || kb.Key == key)
+	})
+	if j < 0 {
+		return Op{}, fmt.Errorf("key binding \"%s\" not found in \"%s\"", name, app)
+	}
+
+	kb := apps[i].Keybinds[j]
+	return Op{App: app, Prefix: apps[i].Prefix, AppIndex: i, Index: j, Before: &kb}, nil
+}
+
+// Apply op. Apps left without key bindings are removed and recreated with
+// their prefix when a key binding is added back
+func (apps *Apps) apply(op Op) error {
+	i := apps.index(op.App)
+
+	if op.Before != nil {
+		if i < 0 {
+			return errChanged
+		}
+		app := (*apps)[i]
+
+		// the key binding is looked up if it moved since op was made
+		j := op.Index
+		if j >= len(app.Keybinds) || !reflect.DeepEqual(app.Keybinds[j], *op.Before) {
+			j = slices.IndexFunc(app.Keybinds, func(kb KeyBind) bool {
+				return reflect.DeepEqual(kb, *op.Before)
+			})
+		}
+		if j < 0 {
+			return errChanged
+		}
+
+		if op.After != nil {
+			app.Keybinds[j] = *op.After
+			return nil
+		}
+		app.Keybinds = slices.Delete(app.Keybinds, j, j+1)
+		if len(app.Keybinds) == 0 {
+			*apps = slices.Delete(*apps, i, i+1)
+		}
+		return nil
+	}
+
+	if op.After == nil {
+		return nil
+	}
+	if i < 0 {
+		i = min(max(op.AppIndex, 0), len(*apps))
+		*apps = slices.Insert(*apps, i, &App{Name: op.App, Prefix: op.Prefix})
+	}
+	app := (*apps)[i]
+	j := min(max(op.Index, 0), len(app.Keybinds))
+	app.Keybinds = slices.Insert(app.Keybinds, j, *op.After)
+	return nil
+}
+
+// Deep copy of apps
+func (apps Apps) clone() Apps {
+	res := make(Apps, len(apps))
+	for i, a := range apps {
+		app := *a
+		app.Keybinds = slices.Clone(a.Keybinds)
+		res[i] = &app
+	}
+	return res
+}
+
+// Editor of the key bindings of a keyb file. Changes are applied in memory
+// and written by Flush, which replays them onto the keyb file as it is on
+// disk. Written changes and undone changes are kept in an undo log in the
+// state dir, so changes made from the command line and the TUI can be undone
+// and redone from either
+type Editor struct {
+	file    string
+	logPath string
+
+	apps Apps
+	undo []Op
+	redo []Op
+
+	// changes since the last flush
+	pending []change
+}
+
+type changeKind int
+
+const (
+	changeEdit changeKind = iota
+	changeUndo
+	changeRedo
+)
+
+type change struct {
+	op   Op
+	kind changeKind
+}
+
+// Undo log of a keyb file with changes that can be undone and redone, most
+// recent last
+type undoLog struct {
+	Undo []Op `json:"undo"`
+	Redo []Op `json:"redo,omitempty"`
+}
+
+// Load editor of keyb file with its undo log in the default state directory
+func LoadEditor(keybFile string) (*Editor, error) {
+	xdgStateDir, err := getXDGStateDir()
+	if err != nil {
+		return nil, err
+	}
+
+	keybFile, err = filepath.Abs(keybFile)
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve keyb file: %w", err)
+	}
+	return NewEditor(keybFile, filepath.Join(xdgStateDir, defaultConfigDir, undoDir, pathKey(keybFile)+".json"))
+}
+
+// Read keyb file and undo log at logPath. A missing keyb file is edited as
+// an empty one
+func NewEditor(keybFile, logPath string) (*Editor, error) {
+	if info, err := os.Stat(keybFile); err == nil && info.IsDir() {
+		return nil, fmt.Errorf("cannot edit keyb directory \"%s\"", keybFile)
+	}
+
+	e := &Editor{file: keybFile, logPath: logPath}
+	if err := e.load(); err != nil {
+		return nil, err
+	}
+	return e, nil
+}
+
+// Read keyb file and undo log from disk
+func (e *Editor) load() error {
+	var apps Apps
+	if _, err := os.Stat(e.file); err == nil {
+		apps, err = readKeyb(e.file, nil)
+		if err != nil {
+			return err
+		}
+	}
+
+	var log undoLog
+	file, err := os.ReadFile(e.logPath)
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return fmt.Errorf("failed to read undo log: %w", err)
+	}
+	if err == nil {
+		if err := json.Unmarshal(file, &log); err != nil {
+			return fmt.Errorf("failed to unmarshal undo log: %w", err)
+		}
+	}
+
+	e.apps, e.undo, e.redo = apps, log.Undo, log.Redo
+	return nil
+}
+
+// Key bindings of the keyb file with all changes applied. The result is a
+// copy and may be modified
+func (e *Editor) Apps() Apps {
+	return e.apps.clone()
+}
+
+// Add kb to the end of app
+func (e *Editor) Add(app string, kb KeyBind) Op {
+	op := e.apps.addOp(app, kb)
+	// adding cannot fail
+	_ = e.do(op)
+	return op
+}
+
+// Remove the first key binding of app with name, and key if not empty
+func (e *Editor) Remove(app, name, key string) (Op, error) {
+	op, err := e.apps.removeOp(app, name, key)
+	if err != nil {
+		return Op{}, err
+	}
+	return op, e.do(op)
+}
+
+func (e *Editor) do(op Op) error {
+	if err := e.apps.apply(op); err != nil {
+		return err
+	}
+	e.undo = append(e.undo, op)
+	e.redo = nil
+	e.pending = append(e.pending, change{op: op})
+	return nil
+}
+
+// Revert the most recent change, including changes already written
+func (e *Editor) Undo() (Op, error) {
+	if len(e.undo) == 0 {
+		return Op{}, errors.New("nothing to undo")
+	}
+
+	op := e.undo[len(e.undo)-1]
+	if err := e.apps.apply(op.Inverse()); err != nil {
+		return Op{}, fmt.Errorf("failed to undo %s: %w", op, err)
+	}
+	e.undo = e.undo[:len(e.undo)-1]
+	e.redo = append(e.redo, op)
+	e.pending = append(e.pending, change{op: op, kind: changeUndo})
+	return op, nil
+}
+
+// Reapply the most recently undone change
+func (e *Editor) Redo() (Op, error) {
+	if len(e.redo) == 0 {
+		return Op{}, errors.New("nothing to redo")
+	}
+
+	op := e.redo[len(e.redo)-1]
+	if err := e.apps.apply(op); err != nil {
+		return Op{}, fmt.Errorf("failed to redo %s: %w", op, err)
+	}
+	e.redo = e.redo[:len(e.redo)-1]
+	e.undo = append(e.undo, op)
+	e.pending = append(e.pending, change{op: op, kind: changeRedo})
+	return op, nil
+}
+
+// Whether there are changes that are not written yet
+func (e *Editor) Dirty() bool {
+	return len(e.pending) > 0
+}
+
+// Write changes to the keyb file and undo log. Changes are replayed onto the
+// files as they are on disk, so changes made by other keyb processes are
+// kept. If a change no longer applies, no changes are written and the editor
+// is reset to the keyb file on disk
+func (e *Editor) Flush() error {
+	if len(e.pending) == 0 {
+		return nil
+	}
+
+	unlock, err := lockKeyb(e.file)
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	pending := e.pending
+	e.pending = nil
+	if err := e.load(); err != nil {
+		return err
+	}
+
+	apps := e.apps.clone()
+	log := undoLog{Undo: slices.Clone(e.undo), Redo: slices.Clone(e.redo)}
+	for _, c := range pending {
+		op := c.op
+		switch c.kind {
+		case changeEdit:
+			log.Undo = append(log.Undo, op)
+			log.Redo = nil
+		case changeUndo:
+			op = op.Inverse()
+			log.Undo = popOp(log.Undo, c.op)
+			log.Redo = append(log.Redo, c.op)
+		case changeRedo:
+			log.Redo = popOp(log.Redo, c.op)
+			log.Undo = append(log.Undo, c.op)
+		}
+
+		if err := apps.apply(op); err != nil {
+			return fmt.Errorf("failed to save %s: %w", op, err)
+		}
+	}
+	log.Undo = lastOps(log.Undo)
+	log.Redo = lastOps(log.Redo)
+
+	data, err := marshalKeyb(e.file, apps)
+	if err != nil {
+		return err
+	}
+	if err := writeKeyb(e.file, data); err != nil {
+		return err
+	}
+
+	logData, err := json.Marshal(log)
+	if err != nil {
+		return fmt.Errorf("failed to marshal undo log: %w", err)
+	}
+	if err := os.MkdirAll(filepath.Dir(e.logPath), 0744); err != nil {
+		return fmt.Errorf("failed to create state dir: %w", err)
+	}
+	if err := writeAtomic(e.logPath, logData, 0644); err != nil {
+		return fmt.Errorf("failed to write undo log: %w", err)
+	}
+
+	e.apps, e.undo, e.redo = apps, log.Undo, log.Redo
+	return nil
+}
+
+// Remove op from the end of ops, if it is the most recent
+func popOp(ops []Op, op Op) []Op {
+	if n := len(ops); n > 0 && reflect.DeepEqual(ops[n-1], op) {
+		return ops[:n-1]
+	}
+	return ops
+}
+
+// Most recent keptOps of ops
+func lastOps(ops []Op) []Op {
+	if len(ops) > keptOps {
+		return ops[len(ops)-keptOps:]
+	}
+	return ops
+}
+
+// Marshal apps in the format of keyb file path
+func marshalKeyb(path string, apps Apps) ([]byte, error) {
+	var (
+		data []byte
+		err  error
+	)
+	switch filepath.Ext(path) {
+	case ".json":
+		data, err = json.MarshalIndent(apps, "", "  ")
+	default:
+		data, err = yaml.Marshal(apps)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal keyb file: %w", err)
+	}
+	return data, nil
+}
@@ -0,0 +1,246 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+const editKeyb = `- name: git
+  prefix: git
+  keybinds:
+  - name: status
+    key: st
+  - name: log
+    key: lg
+- name: vim
+  keybinds:
+  - name: save
+    key: :w
+`
+
+func newTestEditor(t *testing.T, dir string) *Editor {
+	t.Helper()
+	e, err := NewEditor(filepath.Join(dir, "keyb.yml"), filepath.Join(dir, "undo.json"))
+	if err != nil {
+		t.Fatalf("unexpected err: %v", err)
+	}
+	return e
+}
+
+func TestEditor(t *testing.T) {
+	dir := t.TempDir()
+	t.Setenv("XDG_STATE_HOME", filepath.Join(dir, "state"))
+	path := filepath.Join(dir, "keyb.yml")
+	writeFile(t, path, editKeyb)
+
+	original, err := UnmarshalKeyb(path, dir)
+	if err != nil {
+		t.Fatalf("unexpected err: %v", err)
+	}
+
+	t.Run("undo and redo before flush", func(t *testing.T) {
+		e := newTestEditor(t, dir)
+		e.Add("vim", KeyBind{Name: "quit", Key: ":q"})
+		if _, err := e.Remove("git", "status", ""); err != nil {
+			t.Fatalf("unexpected err: %v", err)
+		}
+		edited := e.Apps()
+
+		for range 2 {
+			if _, err := e.Undo(); err != nil {
+				t.Fatalf("unexpected err: %v", err)
+			}
+		}
+		if got := e.Apps(); !reflect.DeepEqual(got, original) {
+			t.Errorf("got %v, want %v", got, original)
+		}
+		if _, err := e.Undo(); err == nil {
+			t.Errorf("expected err")
+		}
+
+		for range 2 {
+			if _, err := e.Redo(); err != nil {
+				t.Fatalf("unexpected err: %v", err)
+			}
+		}
+		if got := e.Apps(); !reflect.DeepEqual(got, edited) {
+			t.Errorf("got %v, want %v", got, edited)
+		}
+	})
+
+	t.Run("undo after flush", func(t *testing.T) {
+		e := newTestEditor(t, dir)
+		if _, err := e.Remove("vim", "save", ""); err != nil {
+			t.Fatalf("unexpected err: %v", err)
+		}
+		if err := e.Flush(); err != nil {
+			t.Fatalf("unexpected err: %v", err)
+		}
+		if got, _ := UnmarshalKeyb(path, dir); len(got) != 1 {
+			t.Errorf("got %v, want vim removed", got)
+		}
+
+		// the undo log is shared with new editors
+		e = newTestEditor(t, dir)
+		if _, err := e.Undo(); err != nil {
+			t.Fatalf("unexpected err: %v", err)
+		}
+		if err := e.Flush(); err != nil {
+			t.Fatalf("unexpected err: %v", err)
+		}
+		if got, _ := UnmarshalKeyb(path, dir); !reflect.DeepEqual(got, original) {
+			t.Errorf("got %v, want %v", got, original)
+		}
+		if e.Dirty() || len(e.undo) != 0 {
+			t.Errorf("got %d pending and %d undo, want none", len(e.pending), len(e.undo))
+		}
+	})
+
+	t.Run("redo after flush", func(t *testing.T) {
+		// the change undone and written above can be redone by new editors
+		e := newTestEditor(t, dir)
+		if _, err := e.Redo(); err != nil {
+			t.Fatalf("unexpected err: %v", err)
+		}
+		if err := e.Flush(); err != nil {
+			t.Fatalf("unexpected err: %v", err)
+		}
+		if got, _ := UnmarshalKeyb(path, dir); len(got) != 1 {
+			t.Errorf("got %v, want vim removed", got)
+		}
+
+		if _, err := e.Undo(); err != nil {
+			t.Fatalf("unexpected err: %v", err)
+		}
+		if err := e.Flush(); err != nil {
+			t.Fatalf("unexpected err: %v", err)
+		}
+		if _, err := e.Redo(); err != nil {
+			t.Fatalf("unexpected err: %v", err)
+		}
+		if _, err := e.Undo(); err != nil {
+			t.Fatalf("unexpected err: %v", err)
+		}
+
+		// new changes clear the changes that can be redone
+		e.Add("vim", KeyBind{Name: "quit", Key: ":q"})
+		if err := e.Flush(); err != nil {
+			t.Fatalf("unexpected err: %v", err)
+		}
+		e = newTestEditor(t, dir)
+		if _, err := e.Redo(); err == nil {
+			t.Errorf("expected err")
+		}
+		if _, err := e.Undo(); err != nil {
+			t.Fatalf("unexpected err: %v", err)
+		}
+		if err := e.Flush(); err != nil {
+			t.Fatalf("unexpected err: %v", err)
+		}
+		if got, _ := UnmarshalKeyb(path, dir); !reflect.DeepEqual(got, original) {
+			t.Errorf("got %v, want %v", got, original)
+		}
+	})
+
+	t.Run("prefix restored", func(t *testing.T) {
+		e := newTestEditor(t, dir)
+		for _, name := range []string{"status", "log"} {
+			if _, err := e.Remove("git", name, ""); err != nil {
+				t.Fatalf("unexpected err: %v", err)
+			}
+		}
+		if got := e.Apps(); len(got) != 1 {
+			t.Errorf("got %v, want git removed", got)
+		}
+
+		for range 2 {
+			if _, err := e.Undo(); err != nil {
+				t.Fatalf("unexpected err: %v", err)
+			}
+		}
+		if got := e.Apps(); !reflect.DeepEqual(got, original) {
+			t.Errorf("got %v, want %v", got, original)
+		}
+	})
+
+	t.Run("flush keeps other changes", func(t *testing.T) {
+		e := newTestEditor(t, dir)
+		e.Add("vim", KeyBind{Name: "quit", Key: ":q"})
+
+		// written by another process in the meantime
+		if err := AddEntry(path, "tmux; split; %", false); err != nil {
+			t.Fatalf("unexpected err: %v", err)
+		}
+		if err := e.Flush(); err != nil {
+			t.Fatalf("unexpected err: %v", err)
+		}
+
+		got, _ := UnmarshalKeyb(path, dir)
+		if len(got) != 3 || len(got[1].Keybinds) != 2 || got[2].Name != "tmux" {
+			t.Errorf("got %v, want quit and tmux added", got)
+		}
+	})
+
+	t.Run("flush fails on conflict", func(t *testing.T) {
+		writeFile(t, path, editKeyb)
+		e := newTestEditor(t, dir)
+		if _, err := e.Remove("vim", "save", ""); err != nil {
+			t.Fatalf("unexpected err: %v", err)
+		}
+
+		writeFile(t, path, "- name: vim\n  keybinds: []\n")
+		if err := e.Flush(); err == nil {
+			t.Errorf("expected err")
+		}
+		if got := e.Apps(); len(got) != 1 || len(got[0].Keybinds) != 0 {
+			t.Errorf("got %v, want keyb file on disk", got)
+		}
+	})
+}
+
+func TestEntryCommands(t *testing.T) {
+	dir := t.TempDir()
+	t.Setenv("XDG_STATE_HOME", filepath.Join(dir, "state"))
+	path := filepath.Join(dir, "keyb.json")
+	writeFile(t, path, `[{"name": "vim", "keybinds": [{"name": "save", "key": ":w"}]}]`)
+
+	if err := AddEntry(path, "vim; quit; :q", false); err != nil {
+		t.Fatalf("unexpected err: %v", err)
+	}
+	if err := RemoveEntry(path, "vim; save"); err != nil {
+		t.Fatalf("unexpected err: %v", err)
+	}
+	if err := RemoveEntry(path, "vim; save"); err == nil {
+		t.Errorf("expected err")
+	}
+
+	// json keyb files stay json
+	got, err := UnmarshalKeyb(path, dir)
+	if err != nil {
+		t.Fatalf("unexpected err: %v", err)
+	}
+	want := Apps{{Name: "vim", Keybinds: []KeyBind{{Name: "quit", Key: ":q"}}}}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got %v, want %v", got, want)
+	}
+
+	op, err := UndoEntry(path)
+	if err != nil {
+		t.Fatalf("unexpected err: %v", err)
+	}
+	if want := `remove "save" from "vim"`; op.String() != want {
+		t.Errorf("got %v, want %v", op, want)
+	}
+	if _, err := UndoEntry(path); err != nil {
+		t.Fatalf("unexpected err: %v", err)
+	}
+
+	data, _ := os.ReadFile(path)
+	got, _ = UnmarshalKeyb(path, dir)
+	want = Apps{{Name: "vim", Keybinds: []KeyBind{{Name: "save", Key: ":w"}}}}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got %s, want %v", data, want)
+	}
+}
@@ -0,0 +1,54 @@
+package config
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Height of keyb's window, in lines or as a percentage of the terminal height
+type Height struct {
+	Value   int
+	Percent bool
+}
+
+// Parse height given as lines ("20") or percentage ("40%"). An empty height
+// is full screen
+func ParseHeight(s string) (Height, error) {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return Height{}, nil
+	}
+
+	var h Height
+	value := s
+	if strings.HasSuffix(s, "%") {
+		h.Percent = true
+		value = strings.TrimSuffix(s, "%")
+	}
+
+	n, err := strconv.Atoi(value)
+	if err != nil || n <= 0 || (h.Percent && n > 100) {
+		return Height{}, fmt.Errorf("invalid height \"%s\"", s)
+	}
+	h.Value = n
+	return h, nil
+}
+
+// Full screen heights are drawn on the alternate screen, others inline
+func (h Height) FullScreen() bool {
+	return h.Value == 0 || (h.Percent && h.Value == 100)
+}
+
+// Number of lines out of the terminal height
+func (h Height) Lines(termHeight int) int {
+	if h.FullScreen() {
+		return termHeight
+	}
+
+	n := h.Value
+	if h.Percent {
+		n = termHeight * h.Value / 100
+	}
+	return max(1, min(n, termHeight))
+}
@@ -0,0 +1,44 @@
+package config
+
+import "testing"
+
+func TestParseHeight(t *testing.T) {
+	tests := []struct {
+		name       string
+		height     string
+		fullScreen bool
+		lines      int
+		wantErr    bool
+	}{
+		{"empty", "", true, 50, false},
+		{"lines", "20", false, 20, false},
+		{"lines exceeding terminal", "80", false, 50, false},
+		{"percent", "40%", false, 20, false},
+		{"full percent", "100%", true, 50, false},
+		{"zero", "0", false, 0, true},
+		{"over 100 percent", "120%", false, 0, true},
+		{"invalid", "foo", false, 0, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseHeight(tt.height)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("expected err")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected err: %v", err)
+			}
+
+			if got.FullScreen() != tt.fullScreen {
+				t.Errorf("got %v, want %v", got.FullScreen(), tt.fullScreen)
+			}
+			if got.Lines(50) != tt.lines {
+				t.Errorf("got %v, want %v", got.Lines(50), tt.lines)
+			}
+		})
+	}
+}
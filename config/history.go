@@ -0,0 +1,117 @@
+package config
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+const defaultHistoryFile = "history"
+
+// History holds submitted search queries, oldest first
+type History struct {
+	path    string
+	size    int
+	entries []string
+}
+
+// Load search history from the default state directory
+func LoadHistory(size int) (*History, error) {
+	xdgStateDir, err := getXDGStateDir()
+	if err != nil {
+		return nil, err
+	}
+	return NewHistory(filepath.Join(xdgStateDir, defaultConfigDir, defaultHistoryFile), size)
+}
+
+// Read history file at path. A missing file results in an empty history and
+// an empty path keeps the history in memory only
+func NewHistory(path string, size int) (*History, error) {
+	h := &History{
+		path: path,
+		size: size,
+	}
+	if path == "" {
+		return h, nil
+	}
+
+	file, err := os.Open(path)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return h, nil
+		}
+		return nil, fmt.Errorf("failed to read history file: %w", err)
+	}
+	defer file.Close()
+
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		if line := scanner.Text(); line != "" {
+			h.entries = append(h.entries, line)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read history file: %w", err)
+	}
+
+	h.trim()
+	return h, nil
+}
+
+// Keep new queries in memory only
+func (h *History) SetReadOnly() {
+	h.path = ""
+}
+
+func (h *History) Entries() []string {
+	return h.entries
+}
+
+func (h *History) Len() int {
+	return len(h.entries)
+}
+
+// Append query to history and persist it. Repeated queries are moved to the
+// end instead of being duplicated
+func (h *History) Add(query string) error {
+	query = strings.TrimSpace(query)
+	if query == "" || h.size <= 0 {
+		return nil
+	}
+
+	for i, e := range h.entries {
+		if e == query {
+			h.entries = append(h.entries[:i], h.entries[i+1:]...)
+			break
+		}
+	}
+	h.entries = append(h.entries, query)
+	h.trim()
+
+	return h.write()
+}
+
+func (h *History) trim() {
+	if h.size > 0 && len(h.entries) > h.size {
+		h.entries = h.entries[len(h.entries)-h.size:]
+	}
+}
+
+func (h *History) write() error {
+	if h.path == "" {
+		return nil
+	}
+
+	if err := os.MkdirAll(filepath.Dir(h.path), 0744); err != nil {
+		return fmt.Errorf("failed to create state dir: %w", err)
+	}
+
+	data := strings.Join(h.entries, "\n") + "\n"
+	if err := os.WriteFile(h.path, []byte(data), 0644); err != nil {
+		return fmt.Errorf("failed to write history file: %w", err)
+	}
+	return nil
+}
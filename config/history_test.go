@@ -0,0 +1,75 @@
+package config
+
+import (
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestHistory(t *testing.T) {
+	t.Run("add and reload", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "state", "history")
+
+		h, err := NewHistory(path, 10)
+		if err != nil {
+			t.Fatalf("unexpected err: %v", err)
+		}
+		for _, q := range []string{"foo", "bar", "foo", "  ", "baz"} {
+			if err := h.Add(q); err != nil {
+				t.Fatalf("unexpected err: %v", err)
+			}
+		}
+
+		got, err := NewHistory(path, 10)
+		if err != nil {
+			t.Fatalf("unexpected err: %v", err)
+		}
+		want := []string{"bar", "foo", "baz"}
+		if !reflect.DeepEqual(got.Entries(), want) {
+			t.Errorf("got %v, want %v", got.Entries(), want)
+		}
+	})
+
+	t.Run("trim to size", func(t *testing.T) {
+		h, err := NewHistory(filepath.Join(t.TempDir(), "history"), 2)
+		if err != nil {
+			t.Fatalf("unexpected err: %v", err)
+		}
+		for _, q := range []string{"foo", "bar", "baz"} {
+			if err := h.Add(q); err != nil {
+				t.Fatalf("unexpected err: %v", err)
+			}
+		}
+
+		want := []string{"bar", "baz"}
+		if !reflect.DeepEqual(h.Entries(), want) {
+			t.Errorf("got %v, want %v", h.Entries(), want)
+		}
+	})
+
+	t.Run("disabled", func(t *testing.T) {
+		h, err := NewHistory(filepath.Join(t.TempDir(), "history"), 0)
+		if err != nil {
+			t.Fatalf("unexpected err: %v", err)
+		}
+		if err := h.Add("foo"); err != nil {
+			t.Fatalf("unexpected err: %v", err)
+		}
+		if h.Len() != 0 {
+			t.Errorf("got %d entries, want 0", h.Len())
+		}
+	})
+
+	t.Run("in memory", func(t *testing.T) {
+		h, err := NewHistory("", 10)
+		if err != nil {
+			t.Fatalf("unexpected err: %v", err)
+		}
+		if err := h.Add("foo"); err != nil {
+			t.Fatalf("unexpected err: %v", err)
+		}
+		if want := []string{"foo"}; !reflect.DeepEqual(h.Entries(), want) {
+			t.Errorf("got %v, want %v", h.Entries(), want)
+		}
+	})
+}
@@ -0,0 +1,503 @@
+package config
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+
+	"gopkg.in/yaml.v2"
+)
+
+const (
+	localConfigFile = ".keyb.yml"
+	envPrefix       = "KEYB_"
+	originDefault   = "default"
+	originFlag      = "flag"
+)
+
+// Layer of config values. Layers are merged in order, with values of later
+// layers replacing those of earlier ones
+type layer struct {
+	origin string
+	tree   map[string]any
+}
+
+// Layer each setting was last set by, keyed by setting name such as
+// settings.padding
+type Origins map[string]string
+
+// Setting of the config file with its name, such as settings.padding, and
+// environment variable
+type setting struct {
+	name  string
+	env   string
+	kind  reflect.Kind
+	index []int
+}
+
+// All settings of the config file in declaration order, except saved queries
+var settings = func() []setting {
+	var res []setting
+	ct := reflect.TypeOf(Config{})
+	for _, section := range []string{"Settings", "Color", "Keys"} {
+		sf, _ := ct.FieldByName(section)
+		prefix := fieldName(sf)
+
+		// settings are unprefixed, e.g. KEYB_PADDING and KEYB_COLOR_PROMPT
+		env := envPrefix
+		if section != "Settings" {
+			env += strings.ToUpper(prefix) + "_"
+		}
+
+		for i := range sf.Type.NumField() {
+			f := sf.Type.Field(i)
+			name := fieldName(f)
+			res = append(res, setting{
+				name:  prefix + "." + name,
+				env:   env + strings.ToUpper(name),
+				kind:  f.Type.Kind(),
+				index: []int{sf.Index[0], i},
+			})
+		}
+	}
+	return res
+}()
+
+// Name of a struct field in config files
+func fieldName(f reflect.StructField) string {
+	if name, _, _ := strings.Cut(f.Tag.Get("yaml"), ","); name != "" {
+		return name
+	}
+	return strings.ToLower(f.Name)
+}
+
+// Resolve config from layers, in order of precedence:
+//
+//   - default config
+//   - config.yml in $XDG_CONFIG_DIRS/keyb
+//   - user config file, or flags.Config if given
+//   - .keyb.yml in the current directory or its parents
+//   - profile selected by flags.Profile or KEYB_PROFILE
+//   - KEYB_* environment variables
+//   - flags.Values, keyed by setting name
+func resolveConfig(flags Flags, basePath string) (*Config, Origins, error) {
+	configFile := flags.Config
+	if configFile == "" {
+		configFile = filepath.Join(basePath, defaultConfigFile)
+	}
+
+	base, err := defaultLayer(basePath)
+	if err != nil {
+		return nil, nil, err
+	}
+	layers := []layer{base}
+
+	var files []string
+	for _, dir := range systemConfigDirs() {
+		files = append(files, filepath.Join(dir, defaultConfigDir, defaultConfigFile))
+	}
+	files = append(files, ExpandPath(configFile, ""))
+
+	// paths in the environment and flags are relative to the working directory
+	var local string
+	wd, err := os.Getwd()
+	if err == nil {
+		if path, ok := findLocalConfig(wd); ok {
+			local = path
+			files = append(files, path)
+		}
+	}
+
+	for _, path := range files {
+		l, ok, err := fileLayer(path)
+		if err != nil {
+			return nil, nil, err
+		}
+		if !ok {
+			continue
+		}
+		if path == local {
+			dropProjectSettings(l.tree)
+		}
+		layers = append(layers, l)
+	}
+
+	profile := flags.Profile
+	if profile == "" {
+		profile = os.Getenv(envPrefix + "PROFILE")
+	}
+	if profile != "" {
+		l, err := profileLayer(profile, layers)
+		if err != nil {
+			return nil, nil, err
+		}
+		layers = append(layers, l)
+	}
+
+	env, err := envLayers(os.LookupEnv)
+	if err != nil {
+		return nil, nil, err
+	}
+	flagLayers, err := valueLayers(flags.Values, originFlag)
+	if err != nil {
+		return nil, nil, err
+	}
+	for _, l := range append(env, flagLayers...) {
+		resolvePaths(l.tree, wd)
+		layers = append(layers, l)
+	}
+
+	return mergeLayers(layers)
+}
+
+// Layer of profile name. A profile may be defined in several layers, which
+// are merged in order
+func profileLayer(name string, layers []layer) (layer, error) {
+	var (
+		tree  = make(map[string]any)
+		found bool
+	)
+	for _, l := range layers {
+		profiles, _ := l.tree["profiles"].(map[string]any)
+		v, ok := profiles[name]
+		if !ok {
+			continue
+		}
+		found = true
+
+		if p, ok := v.(map[string]any); ok {
+			merge(tree, p, "", "", make(Origins))
+		}
+	}
+	if !found {
+		return layer{}, fmt.Errorf("profile \"%s\" not found", name)
+	}
+
+	// profiles do not nest
+	delete(tree, "profiles")
+	if err := decodeTree(tree, &Config{}); err != nil {
+		return layer{}, fmt.Errorf("invalid profile \"%s\": %w", name, err)
+	}
+	return layer{origin: "profile " + name, tree: tree}, nil
+}
+
+func mergeLayers(layers []layer) (*Config, Origins, error) {
+	tree := make(map[string]any)
+	origins := make(Origins)
+	for _, l := range layers {
+		merge(tree, l.tree, "", l.origin, origins)
+	}
+
+	res := &Config{}
+	if err := decodeTree(tree, res); err != nil {
+		return nil, nil, fmt.Errorf("failed to resolve config: %w", err)
+	}
+	return res, origins, nil
+}
+
+// Merge src into dst, recording the origin of each value. Maps are merged
+// key by key, while other values, including lists, are replaced. Null values
+// are unset and leave dst unchanged, while zero values such as 0, false and ""
+// replace it
+func merge(dst, src map[string]any, prefix, origin string, origins Origins) {
+	for k, v := range src {
+		if v == nil {
+			continue
+		}
+
+		name := prefix + k
+		if m, ok := v.(map[string]any); ok {
+			d, ok := dst[k].(map[string]any)
+			if !ok {
+				d = make(map[string]any)
+				dst[k] = d
+			}
+			merge(d, m, name+".", origin, origins)
+			continue
+		}
+		dst[k] = v
+		origins[name] = origin
+	}
+}
+
+// Decode tree into config with a JSON round trip
+func decodeTree(tree map[string]any, c *Config) error {
+	data, err := json.Marshal(tree)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(data, c)
+}
+
+// Tree of config, keyed by setting names
+func configTree(c *Config) (map[string]any, error) {
+	data, err := yaml.Marshal(c)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal config: %w", err)
+	}
+
+	var tree any
+	if err := yaml.Unmarshal(data, &tree); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal config: %w", err)
+	}
+	m, _ := normalize(tree).(map[string]any)
+	return m, nil
+}
+
+// Convert YAML maps to maps with string keys so trees can be encoded as JSON
+func normalize(v any) any {
+	switch v := v.(type) {
+	case map[any]any:
+		m := make(map[string]any, len(v))
+		for k, val := range v {
+			m[fmt.Sprint(k)] = normalize(val)
+		}
+		return m
+	case map[string]any:
+		for k, val := range v {
+			v[k] = normalize(val)
+		}
+		return v
+	case []any:
+		for i, val := range v {
+			v[i] = normalize(val)
+		}
+		return v
+	default:
+		return v
+	}
+}
+
+func defaultLayer(basePath string) (layer, error) {
+	tree, err := configTree(newDefaultConfig(basePath))
+	if err != nil {
+		return layer{}, err
+	}
+	return layer{origin: originDefault, tree: tree}, nil
+}
+
+// Read config file at path. Missing files and files of unknown formats are
+// skipped. Paths in the file are resolved against its directory
+func fileLayer(path string) (layer, bool, error) {
+	path, err := filepath.Abs(path)
+	if err != nil {
+		return layer{}, false, fmt.Errorf("failed to resolve config file \"%s\": %w", path, err)
+	}
+
+	file, err := os.ReadFile(path)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return layer{}, false, nil
+		}
+		return layer{}, false, fmt.Errorf("failed to read config file \"%s\": %w", path, err)
+	}
+
+	var tree any
+	switch filepath.Ext(path) {
+	case ".json":
+		err = json.Unmarshal(file, &tree)
+	case ".yaml", ".yml":
+		err = yaml.Unmarshal(file, &tree)
+	default:
+		return layer{}, false, nil
+	}
+	if err != nil {
+		return layer{}, false, fmt.Errorf("failed to unmarshal config file \"%s\": %w", path, err)
+	}
+
+	m, ok := normalize(tree).(map[string]any)
+	if tree != nil && !ok {
+		return layer{}, false, fmt.Errorf("failed to unmarshal config file \"%s\": not a mapping", path)
+	}
+
+	// check types of values before they are merged with other layers
+	if err := decodeTree(m, &Config{}); err != nil {
+		return layer{}, false, fmt.Errorf("failed to unmarshal config file \"%s\": %w", path, err)
+	}
+
+	resolvePaths(m, filepath.Dir(path))
+	return layer{origin: path, tree: m}, true, nil
+}
+
+// Config directories of $XDG_CONFIG_DIRS, least important first
+func systemConfigDirs() []string {
+	val := os.Getenv("XDG_CONFIG_DIRS")
+	if val == "" {
+		val = "/etc/xdg"
+	}
+
+	var res []string
+	for _, dir := range filepath.SplitList(val) {
+		if dir != "" {
+			res = append([]string{dir}, res...)
+		}
+	}
+	return res
+}
+
+// Find .keyb.yml with config in dir or its parents. Files with a list of key
+// bindings at the root are skipped
+func findLocalConfig(dir string) (string, bool) {
+	for {
+		path := filepath.Join(dir, localConfigFile)
+		if file, err := os.ReadFile(path); err == nil {
+			var tree any
+			if err := yaml.Unmarshal(file, &tree); err == nil {
+				if _, ok := tree.(map[any]any); ok {
+					return path, true
+				}
+			}
+		}
+
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return "", false
+		}
+		dir = parent
+	}
+}
+
+// Layers of settings given as KEYB_* environment variables. Empty variables
+// are unset, except for text settings which are set to ""
+func envLayers(lookup func(string) (string, bool)) ([]layer, error) {
+	var res []layer
+	for _, s := range settings {
+		val, ok := lookup(s.env)
+		if !ok || (val == "" && s.kind != reflect.String) {
+			continue
+		}
+
+		v, err := parseValue(s, val)
+		if err != nil {
+			return nil, fmt.Errorf("invalid value %q for %s: %w", val, s.env, err)
+		}
+		res = append(res, layer{origin: "env " + s.env, tree: settingTree(s.name, v)})
+	}
+	return res, nil
+}
+
+// Layers of values keyed by setting name
+func valueLayers(values map[string]string, origin string) ([]layer, error) {
+	var res []layer
+	for _, s := range settings {
+		val, ok := values[s.name]
+		if !ok {
+			continue
+		}
+
+		v, err := parseValue(s, val)
+		if err != nil {
+			return nil, fmt.Errorf("invalid value %q for %s: %w", val, s.name, err)
+		}
+		res = append(res, layer{origin: origin, tree: settingTree(s.name, v)})
+	}
+	return res, nil
+}
+
+// Parse string value of setting to the setting's type. Lists are given as
+// YAML or JSON
+func parseValue(s setting, val string) (any, error) {
+	switch s.kind {
+	case reflect.Bool:
+		return strconv.ParseBool(val)
+	case reflect.Int:
+		return strconv.Atoi(val)
+	case reflect.String:
+		return val, nil
+	default:
+		var v any
+		if err := yaml.Unmarshal([]byte(val), &v); err != nil {
+			return nil, err
+		}
+		return normalize(v), nil
+	}
+}
+
+// Tree with a single value at setting name
+func settingTree(name string, v any) map[string]any {
+	section, key, _ := strings.Cut(name, ".")
+	return map[string]any{section: map[string]any{key: v}}
+}
+
+// Origin of setting name
+func (c *Config) Origin(name string) string {
+	if origin, ok := c.origins[name]; ok {
+		return origin
+	}
+	return originDefault
+}
+
+// Write effective value of every setting, with the layer it came from if
+// origin is true
+func (c *Config) Show(w io.Writer, origin bool) error {
+	tw := tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+	show := func(name string, v any) error {
+		var buf bytes.Buffer
+		enc := json.NewEncoder(&buf)
+		enc.SetEscapeHTML(false)
+		if err := enc.Encode(v); err != nil {
+			return fmt.Errorf("failed to marshal %s: %w", name, err)
+		}
+
+		value := strings.TrimSuffix(buf.String(), "\n")
+		if origin {
+			fmt.Fprintf(tw, "%s\t%s\t%s\n", name, value, c.Origin(name))
+		} else {
+			fmt.Fprintf(tw, "%s\t%s\n", name, value)
+		}
+		return nil
+	}
+
+	cv := reflect.ValueOf(c).Elem()
+	for _, s := range settings {
+		v := cv.FieldByIndex(s.index)
+		if v.Kind() == reflect.Slice && v.IsNil() {
+			v = reflect.MakeSlice(v.Type(), 0, 0)
+		}
+		if err := show(s.name, v.Interface()); err != nil {
+			return err
+		}
+	}
+
+	names := make([]string, 0, len(c.Saved))
+	for name := range c.Saved {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		if err := show("saved."+name, c.Saved[name]); err != nil {
+			return err
+		}
+	}
+	return tw.Flush()
+}
+
+// settings a project .keyb.yml cannot set, as they decide which project keyb
+// files are trusted and read
+var projectIgnored = []string{"trusted_dirs", "keyb_path"}
+
+// Remove settings that are ignored in project config files from tree and its
+// profiles
+func dropProjectSettings(tree map[string]any) {
+	if s, ok := tree["settings"].(map[string]any); ok {
+		for _, name := range projectIgnored {
+			delete(s, name)
+		}
+	}
+
+	profiles, _ := tree["profiles"].(map[string]any)
+	for _, p := range profiles {
+		if p, ok := p.(map[string]any); ok {
+			dropProjectSettings(p)
+		}
+	}
+}
@@ -0,0 +1,355 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func writeFile(t *testing.T, path, data string) {
+	t.Helper()
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		t.Fatalf("unexpected err: %v", err)
+	}
+	if err := os.WriteFile(path, []byte(data), 0644); err != nil {
+		t.Fatalf("unexpected err: %v", err)
+	}
+}
+
+func TestResolveConfig(t *testing.T) {
+	dir := t.TempDir()
+	basePath := filepath.Join(dir, "home", "keyb")
+
+	writeFile(t, filepath.Join(dir, "etc", "keyb", "config.yml"),
+		"settings:\n  margin: 1\n  padding: 1\n  title: system\n  reverse: true\n")
+	writeFile(t, filepath.Join(basePath, "config.yml"),
+		"settings:\n  padding: 2\n  title: user\nsaved:\n  vim: vim\n")
+	writeFile(t, filepath.Join(dir, "project", ".keyb.yml"),
+		"settings:\n  title: project\n  prompt: local\n")
+	// key bindings are not config
+	writeFile(t, filepath.Join(dir, "project", "sub", ".keyb.yml"),
+		"- name: app\n  keybinds: []\n")
+
+	t.Setenv("XDG_CONFIG_DIRS", filepath.Join(dir, "etc"))
+	t.Setenv("KEYB_PROMPT", "env")
+	t.Setenv("KEYB_COLOR_PROMPT", "#fff")
+	t.Setenv("KEYB_KEYS_QUIT", "x")
+	t.Setenv("KEYB_HEIGHT", "20")
+	t.Chdir(filepath.Join(dir, "project", "sub"))
+
+	got, origins, err := resolveConfig(Flags{Values: map[string]string{"settings.height": "10"}}, basePath)
+	if err != nil {
+		t.Fatalf("unexpected err: %v", err)
+	}
+
+	tests := []struct {
+		name   string
+		got    any
+		want   any
+		origin string
+	}{
+		{"settings.margin", got.Margin, 1, filepath.Join(dir, "etc", "keyb", "config.yml")},
+		{"settings.reverse", got.Reverse, true, filepath.Join(dir, "etc", "keyb", "config.yml")},
+		{"settings.padding", got.Padding, 2, filepath.Join(basePath, "config.yml")},
+		{"settings.title", got.Title, "project", filepath.Join(dir, "project", ".keyb.yml")},
+		{"settings.prompt", got.Prompt, "env", "env KEYB_PROMPT"},
+		{"settings.height", got.Height, "10", "flag"},
+		{"settings.sep_width", got.SepWidth, 4, "default"},
+		{"color.prompt", got.PromptColor, "#fff", "env KEYB_COLOR_PROMPT"},
+		{"keys.quit", got.Quit, "x", "env KEYB_KEYS_QUIT"},
+		{"saved.vim", got.Saved["vim"], "vim", filepath.Join(basePath, "config.yml")},
+	}
+	got.origins = origins
+	for _, tt := range tests {
+		if !reflect.DeepEqual(tt.got, tt.want) {
+			t.Errorf("%s: got %v, want %v", tt.name, tt.got, tt.want)
+		}
+		if o := got.Origin(tt.name); o != tt.origin {
+			t.Errorf("%s: got origin %v, want %v", tt.name, o, tt.origin)
+		}
+	}
+}
+
+func TestEnvLayers(t *testing.T) {
+	env := map[string]string{
+		"KEYB_MOUSE":   "false",
+		"KEYB_COLUMNS": "[{field: key}, {field: name}]",
+	}
+	lookup := func(k string) (string, bool) {
+		v, ok := env[k]
+		return v, ok
+	}
+
+	layers, err := envLayers(lookup)
+	if err != nil {
+		t.Fatalf("unexpected err: %v", err)
+	}
+	got, _, err := mergeLayers(layers)
+	if err != nil {
+		t.Fatalf("unexpected err: %v", err)
+	}
+
+	want := Settings{
+		Mouse:   false,
+		Columns: []Column{{Field: "key"}, {Field: "name"}},
+	}
+	if !reflect.DeepEqual(got.Settings, want) {
+		t.Errorf("got %v, want %v", got.Settings, want)
+	}
+
+	t.Run("empty value", func(t *testing.T) {
+		env["KEYB_MARGIN"] = ""
+		env["KEYB_TITLE"] = ""
+		layers, err := envLayers(lookup)
+		if err != nil {
+			t.Fatalf("unexpected err: %v", err)
+		}
+
+		// only text settings are set by empty variables
+		_, origins, err := mergeLayers(layers)
+		if err != nil {
+			t.Fatalf("unexpected err: %v", err)
+		}
+		if _, ok := origins["settings.margin"]; ok {
+			t.Errorf("got margin set, want unset")
+		}
+		if got := origins["settings.title"]; got != "env KEYB_TITLE" {
+			t.Errorf("got %v, want %v", got, "env KEYB_TITLE")
+		}
+	})
+
+	t.Run("invalid value", func(t *testing.T) {
+		env["KEYB_PADDING"] = "wide"
+		_, err := envLayers(lookup)
+		if err == nil || !strings.Contains(err.Error(), "KEYB_PADDING") {
+			t.Errorf("got %v, want error for KEYB_PADDING", err)
+		}
+	})
+}
+
+func TestShow(t *testing.T) {
+	c, origins, err := mergeLayers([]layer{
+		{origin: "default", tree: map[string]any{"settings": map[string]any{"prompt": "> "}}},
+		{origin: "flag", tree: map[string]any{"settings": map[string]any{"padding": 3}}},
+	})
+	if err != nil {
+		t.Fatalf("unexpected err: %v", err)
+	}
+	c.origins = origins
+
+	var sb strings.Builder
+	if err := c.Show(&sb, true); err != nil {
+		t.Fatalf("unexpected err: %v", err)
+	}
+
+	lines := strings.Split(sb.String(), "\n")
+	for _, want := range [][]string{
+		{"settings.prompt", `"> "`, "default"},
+		{"settings.padding", "3", "flag"},
+		{"settings.columns", "[]", "default"},
+	} {
+		found := false
+		for _, line := range lines {
+			if reflect.DeepEqual(strings.Fields(line), strings.Fields(strings.Join(want, " "))) {
+				found = true
+			}
+		}
+		if !found {
+			t.Errorf("got %q, want line %v", sb.String(), want)
+		}
+	}
+}
+
+func TestProfiles(t *testing.T) {
+	dir := t.TempDir()
+	basePath := filepath.Join(dir, "keyb")
+	userConfig := filepath.Join(basePath, "config.yml")
+
+	writeFile(t, filepath.Join(dir, "etc", "keyb", "config.yml"),
+		"profiles:\n  talk:\n    settings:\n      margin: 4\n")
+	writeFile(t, userConfig, `
+settings:
+  padding: 1
+  mouse: true
+profiles:
+  talk:
+    settings:
+      padding: 6
+      mouse: false
+      keyb_path: talk.yml
+    color:
+      prompt: "#fff"
+  work:
+    keys:
+      quit: x
+`)
+	t.Setenv("XDG_CONFIG_DIRS", filepath.Join(dir, "etc"))
+	t.Chdir(dir)
+
+	t.Run("flag", func(t *testing.T) {
+		t.Setenv("KEYB_PROFILE", "work")
+		got, origins, err := resolveConfig(Flags{Profile: "talk"}, basePath)
+		if err != nil {
+			t.Fatalf("unexpected err: %v", err)
+		}
+		got.origins = origins
+
+		want := []any{6, false, filepath.Join(basePath, "talk.yml"), 4, "#fff", "q, ctrl+c"}
+		if g := []any{got.Padding, got.Mouse, got.KeybPath, got.Margin, got.PromptColor, got.Quit}; !reflect.DeepEqual(g, want) {
+			t.Errorf("got %v, want %v", g, want)
+		}
+		if o := got.Origin("settings.padding"); o != "profile talk" {
+			t.Errorf("got origin %v, want %v", o, "profile talk")
+		}
+	})
+
+	t.Run("env", func(t *testing.T) {
+		t.Setenv("KEYB_PROFILE", "work")
+		got, _, err := resolveConfig(Flags{}, basePath)
+		if err != nil {
+			t.Fatalf("unexpected err: %v", err)
+		}
+		if got.Quit != "x" || got.Padding != 1 {
+			t.Errorf("got quit %v padding %v, want x and 1", got.Quit, got.Padding)
+		}
+	})
+
+	t.Run("env overrides profile", func(t *testing.T) {
+		t.Setenv("KEYB_PADDING", "2")
+		got, _, err := resolveConfig(Flags{Profile: "talk"}, basePath)
+		if err != nil {
+			t.Fatalf("unexpected err: %v", err)
+		}
+		if got.Padding != 2 {
+			t.Errorf("got %v, want %v", got.Padding, 2)
+		}
+	})
+
+	t.Run("not found", func(t *testing.T) {
+		_, _, err := resolveConfig(Flags{Profile: "home"}, basePath)
+		if err == nil {
+			t.Errorf("expected err")
+		}
+	})
+}
+
+func TestMergeLayers(t *testing.T) {
+	settingsLayer := func(origin string, s map[string]any) layer {
+		return layer{origin: origin, tree: map[string]any{"settings": s}}
+	}
+	base := settingsLayer("default", map[string]any{
+		"padding":      1,
+		"mouse":        true,
+		"title":        "keyb",
+		"trusted_dirs": []any{"/a", "/b"},
+	})
+
+	tests := []struct {
+		name   string
+		layers []layer
+		check  func(*Config) any
+		want   any
+		setter string
+		origin string
+	}{
+		{
+			name:   "unset keeps lower layer",
+			layers: []layer{base, settingsLayer("user", map[string]any{"margin": 2})},
+			check:  func(c *Config) any { return c.Padding },
+			want:   1,
+			setter: "settings.padding",
+			origin: "default",
+		},
+		{
+			name:   "null is unset",
+			layers: []layer{base, settingsLayer("user", map[string]any{"padding": nil})},
+			check:  func(c *Config) any { return c.Padding },
+			want:   1,
+			setter: "settings.padding",
+			origin: "default",
+		},
+		{
+			name:   "zero int",
+			layers: []layer{base, settingsLayer("user", map[string]any{"padding": 0})},
+			check:  func(c *Config) any { return c.Padding },
+			want:   0,
+			setter: "settings.padding",
+			origin: "user",
+		},
+		{
+			name:   "false",
+			layers: []layer{base, settingsLayer("user", map[string]any{"mouse": false})},
+			check:  func(c *Config) any { return c.Mouse },
+			want:   false,
+			setter: "settings.mouse",
+			origin: "user",
+		},
+		{
+			name:   "empty string",
+			layers: []layer{base, settingsLayer("user", map[string]any{"title": ""})},
+			check:  func(c *Config) any { return c.Title },
+			want:   "",
+			setter: "settings.title",
+			origin: "user",
+		},
+		{
+			name: "later layer wins",
+			layers: []layer{
+				base,
+				settingsLayer("user", map[string]any{"padding": 2}),
+				settingsLayer("profile talk", map[string]any{"padding": 0}),
+				settingsLayer("env KEYB_PADDING", map[string]any{"padding": 3}),
+			},
+			check:  func(c *Config) any { return c.Padding },
+			want:   3,
+			setter: "settings.padding",
+			origin: "env KEYB_PADDING",
+		},
+		{
+			name:   "lists are replaced",
+			layers: []layer{base, settingsLayer("user", map[string]any{"trusted_dirs": []any{"/c"}})},
+			check:  func(c *Config) any { return c.TrustedDirs },
+			want:   []string{"/c"},
+			setter: "settings.trusted_dirs",
+			origin: "user",
+		},
+		{
+			name:   "empty list",
+			layers: []layer{base, settingsLayer("user", map[string]any{"trusted_dirs": []any{}})},
+			check:  func(c *Config) any { return c.TrustedDirs },
+			want:   []string{},
+			setter: "settings.trusted_dirs",
+			origin: "user",
+		},
+		{
+			name: "maps are merged",
+			layers: []layer{
+				{origin: "user", tree: map[string]any{"saved": map[string]any{"a": "foo", "b": "bar"}}},
+				{origin: "local", tree: map[string]any{"saved": map[string]any{"b": "baz"}}},
+			},
+			check:  func(c *Config) any { return c.Saved },
+			want:   map[string]string{"a": "foo", "b": "baz"},
+			setter: "saved.a",
+			origin: "user",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c, origins, err := mergeLayers(tt.layers)
+			if err != nil {
+				t.Fatalf("unexpected err: %v", err)
+			}
+			c.origins = origins
+
+			if got := tt.check(c); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("got %v, want %v", got, tt.want)
+			}
+			if got := c.Origin(tt.setter); got != tt.origin {
+				t.Errorf("got origin %v, want %v", got, tt.origin)
+			}
+		})
+	}
+}
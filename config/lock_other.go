@@ -0,0 +1,9 @@
+//go:build !unix
+
+package config
+
+// Advisory locks are only supported on unix, elsewhere concurrent writes are
+// not guarded against
+func lockFile(path string) (func(), error) {
+	return func() {}, nil
+}
@@ -0,0 +1,38 @@
+//go:build unix
+
+package config
+
+import (
+	"fmt"
+	"path/filepath"
+	"sync"
+	"testing"
+)
+
+func TestAddEntryConcurrent(t *testing.T) {
+	dir := t.TempDir()
+	t.Setenv("XDG_STATE_HOME", filepath.Join(dir, "state"))
+	t.Setenv("XDG_CONFIG_HOME", filepath.Join(dir, "config"))
+	path := filepath.Join(dir, "keyb.yml")
+
+	const n = 10
+	var wg sync.WaitGroup
+	for i := range n {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if err := AddEntry(path, fmt.Sprintf("app; name %d; key", i), false); err != nil {
+				t.Errorf("unexpected err: %v", err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	apps, err := UnmarshalKeyb(path, dir)
+	if err != nil {
+		t.Fatalf("unexpected err: %v", err)
+	}
+	if len(apps) != 1 || len(apps[0].Keybinds) != n {
+		t.Errorf("got %v, want %d key bindings", apps, n)
+	}
+}
@@ -2,20 +2,29 @@ package config
 
 import (
 	"fmt"
-	"os"
+	"path/filepath"
 	"strings"
-
-	"gopkg.in/yaml.v2"
 )
 
 type App struct {
 	Prefix   string    `yaml:"prefix,omitempty" json:"prefix,omitempty"`
 	Name     string    `yaml:"name" json:"name"`
 	Keybinds []KeyBind `yaml:"keybinds" json:"keybinds"`
+
+	// project keyb file the app was read from, empty for the user keyb file
+	Origin string `yaml:"-" json:"-"`
 }
 
 type Apps []*App
 
+// Heading of the app. Project apps are marked with their project directory
+func (a App) Heading() string {
+	if a.Origin == "" {
+		return a.Name
+	}
+	return fmt.Sprintf("%s [%s]", a.Name, filepath.Base(filepath.Dir(a.Origin)))
+}
+
 func (a App) String() string {
 	return fmt.Sprintf("App{name=%s,prefix=%s,keybinds=%v}", a.Name, a.Prefix, a.Keybinds)
 }
@@ -24,81 +33,80 @@ type KeyBind struct {
 	Name string `yaml:"name" json:"name"`
 	Key  string `yaml:"key" json:"key"`
 
+	// optional fields that can be shown as columns
+	Mode  string   `yaml:"mode,omitempty" json:"mode,omitempty"`
+	Tags  []string `yaml:"tags,omitempty" json:"tags,omitempty"`
+	Notes string   `yaml:"notes,omitempty" json:"notes,omitempty"`
+
 	// ignore prefix defaults to false
 	// so user can choose to ignore prefix for a specific kb
 	IgnorePrefix bool `yaml:"ignore_prefix,omitempty" json:"ignore_prefix,omitempty"`
 }
 
+// Add key binding given as "app; name; key" to the keyb file at path
 func AddEntry(path, binding string, kbIgnorePrefix bool) error {
-	xdgConfigDir, err := getXDGConfigDir()
+	s, err := splitBinding(binding, 3)
 	if err != nil {
-		return err
+		return fmt.Errorf("binding must be given in format [app; name; keybind]")
 	}
 
-	// load existing struct from filepath
-	apps, err := UnmarshalKeyb(path, xdgConfigDir)
+	e, err := LoadEditor(ExpandPath(path, ""))
 	if err != nil {
 		return err
 	}
+	e.Add(s[0], KeyBind{Name: s[1], Key: s[2], IgnorePrefix: kbIgnorePrefix})
+	return e.Flush()
+}
 
-	if binding == "" {
-		return fmt.Errorf("binding must be given in format [app; name; keybind]")
+// Remove key binding given as "app; name" from the keyb file at path
+func RemoveEntry(path, binding string) error {
+	s, err := splitBinding(binding, 2)
+	if err != nil {
+		return fmt.Errorf("binding must be given in format [app; name]")
 	}
 
-	s := strings.Split(binding, ";")
-	if len(s) < 3 {
-		return fmt.Errorf("binding must be given in format [app; name; keybind]")
+	e, err := LoadEditor(ExpandPath(path, ""))
+	if err != nil {
+		return err
 	}
+	if _, err := e.Remove(s[0], s[1], ""); err != nil {
+		return err
+	}
+	return e.Flush()
+}
 
-	appName := strings.TrimSpace(s[0])
-	name := strings.TrimSpace(s[1])
-	key := strings.TrimSpace(s[2])
-
-	apps.addOrUpdate(appName, name, key, kbIgnorePrefix)
-
-	// rewrite file
-	data, err := yaml.Marshal(apps)
+// Revert the most recent change to the keyb file at path
+func UndoEntry(path string) (Op, error) {
+	e, err := LoadEditor(ExpandPath(path, ""))
 	if err != nil {
-		return fmt.Errorf("failed to marshal entry: %w", err)
+		return Op{}, err
 	}
 
-	path = os.ExpandEnv(path)
-	if err := os.WriteFile(path, data, 0644); err != nil {
-		return fmt.Errorf("failed to write keyb file: %w", err)
+	op, err := e.Undo()
+	if err != nil {
+		return Op{}, err
 	}
+	return op, e.Flush()
+}
 
-	return nil
+// Split binding into n trimmed fields separated by ";"
+func splitBinding(binding string, n int) ([]string, error) {
+	s := strings.Split(binding, ";")
+	if binding == "" || len(s) < n {
+		return nil, fmt.Errorf("invalid binding \"%s\"", binding)
+	}
+	for i := range s {
+		s[i] = strings.TrimSpace(s[i])
+	}
+	return s[:n], nil
 }
 
 func (apps *Apps) addOrUpdate(appName string, name, key string, ignorePrefix bool) {
-	newKeyBind := KeyBind{
+	op := apps.addOp(appName, KeyBind{
 		Name:         name,
 		Key:          key,
 		IgnorePrefix: ignorePrefix,
-	}
-
-	if !apps.exist(appName) {
-		a := App{
-			Name:     appName,
-			Keybinds: []KeyBind{newKeyBind},
-		}
-		*apps = append(*apps, &a)
-
-	} else {
-		for _, app := range *apps {
-			if appName == app.Name {
-				app.Keybinds = append(app.Keybinds, newKeyBind)
-			}
-		}
-	}
-	// return apps
-}
-
-func (apps Apps) exist(appName string) bool {
-	for _, app := range apps {
-		if appName == app.Name {
-			return true
-		}
-	}
-	return false
+	})
+	// adding cannot fail
+	_ = apps.apply(op)
 }
@@ -0,0 +1,90 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// Expand environment variables and a leading ~ in path. Relative paths are
+// resolved against base, the directory of the file they were given in, unless
+// base is empty
+func ExpandPath(path, base string) string {
+	path = os.ExpandEnv(path)
+
+	if path == "~" || strings.HasPrefix(path, "~/") {
+		if home, err := os.UserHomeDir(); err == nil {
+			path = filepath.Join(home, path[1:])
+		}
+	}
+
+	if base != "" && !filepath.IsAbs(path) {
+		path = filepath.Join(base, path)
+	}
+	return path
+}
+
+// Find keyb file at path. Relative paths are searched in base, then in the
+// keyb directory of $XDG_DATA_HOME and $XDG_DATA_DIRS. If none exist, path is
+// resolved against base
+func FindKeyb(path, base string) string {
+	expanded := ExpandPath(path, "")
+	if filepath.IsAbs(expanded) {
+		return expanded
+	}
+
+	candidates := []string{ExpandPath(expanded, base)}
+	for _, dir := range dataDirs() {
+		candidates = append(candidates, filepath.Join(dir, defaultConfigDir, expanded))
+	}
+	for _, c := range candidates {
+		if _, err := os.Stat(c); err == nil {
+			return c
+		}
+	}
+	return candidates[0]
+}
+
+// Data directories of $XDG_DATA_HOME and $XDG_DATA_DIRS, most important first
+func dataDirs() []string {
+	var res []string
+	if dir, err := getXDGDataDir(); err == nil {
+		res = append(res, dir)
+	}
+
+	val := os.Getenv("XDG_DATA_DIRS")
+	if val == "" {
+		val = "/usr/local/share:/usr/share"
+	}
+	for _, dir := range filepath.SplitList(val) {
+		if dir != "" {
+			res = append(res, dir)
+		}
+	}
+	return res
+}
+
+// Resolve path settings of tree against base. Paths in config files are
+// relative to the file, while paths given in the environment or as flags are
+// relative to the working directory
+func resolvePaths(tree map[string]any, base string) {
+	if s, ok := tree["settings"].(map[string]any); ok {
+		if path, ok := s["keyb_path"].(string); ok && path != "" {
+			s["keyb_path"] = FindKeyb(path, base)
+		}
+		if dirs, ok := s["trusted_dirs"].([]any); ok {
+			for i, d := range dirs {
+				if d, ok := d.(string); ok && d != "" {
+					dirs[i] = ExpandPath(d, base)
+				}
+			}
+		}
+	}
+
+	profiles, _ := tree["profiles"].(map[string]any)
+	for _, p := range profiles {
+		if p, ok := p.(map[string]any); ok {
+			resolvePaths(p, base)
+		}
+	}
+}
@@ -0,0 +1,145 @@
+package config
+
+import (
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestExpandPath(t *testing.T) {
+	t.Setenv("HOME", "/home/user")
+	t.Setenv("KEYB_TEST_DIR", "/data")
+
+	tests := []struct {
+		name string
+		path string
+		base string
+		want string
+	}{
+		{"home", "~", "", "/home/user"},
+		{"home prefix", "~/keyb.yml", "/etc", "/home/user/keyb.yml"},
+		{"env", "$KEYB_TEST_DIR/keyb.yml", "/etc", "/data/keyb.yml"},
+		{"relative", "keyb.yml", "/etc/keyb", "/etc/keyb/keyb.yml"},
+		{"parent", "../keyb.yml", "/etc/keyb", "/etc/keyb.yml"},
+		{"relative without base", "./keyb.yml", "", "./keyb.yml"},
+		{"absolute", "/keyb.yml", "/etc", "/keyb.yml"},
+		{"tilde in name", "~user/keyb.yml", "/etc", "/etc/~user/keyb.yml"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := ExpandPath(tt.path, tt.base); got != tt.want {
+				t.Errorf("got %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFindKeyb(t *testing.T) {
+	dir := t.TempDir()
+	base := filepath.Join(dir, "config")
+	dataHome := filepath.Join(dir, "home")
+	system := filepath.Join(dir, "usr")
+
+	writeFile(t, filepath.Join(base, "own.yml"), "")
+	writeFile(t, filepath.Join(dataHome, "keyb", "vim.yml"), "")
+	writeFile(t, filepath.Join(system, "keyb", "vim.yml"), "")
+	writeFile(t, filepath.Join(system, "keyb", "git.yml"), "")
+
+	t.Setenv("XDG_DATA_HOME", dataHome)
+	t.Setenv("XDG_DATA_DIRS", filepath.Join(dir, "missing")+string(filepath.ListSeparator)+system)
+
+	tests := []struct {
+		name string
+		path string
+		want string
+	}{
+		{"base", "own.yml", filepath.Join(base, "own.yml")},
+		{"data home", "vim.yml", filepath.Join(dataHome, "keyb", "vim.yml")},
+		{"data dirs", "git.yml", filepath.Join(system, "keyb", "git.yml")},
+		{"not found", "tmux.yml", filepath.Join(base, "tmux.yml")},
+		{"absolute", filepath.Join(dir, "vim.yml"), filepath.Join(dir, "vim.yml")},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := FindKeyb(tt.path, base); got != tt.want {
+				t.Errorf("got %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestResolvePaths(t *testing.T) {
+	dir := t.TempDir()
+	basePath := filepath.Join(dir, "keyb")
+
+	writeFile(t, filepath.Join(basePath, "config.yml"), `
+settings:
+  keyb_path: sheets
+  trusted_dirs: [../work, /src]
+profiles:
+  talk:
+    settings:
+      keyb_path: talk.yml
+`)
+	t.Setenv("XDG_CONFIG_DIRS", filepath.Join(dir, "etc"))
+	t.Setenv("XDG_DATA_HOME", filepath.Join(dir, "data"))
+	t.Setenv("XDG_DATA_DIRS", filepath.Join(dir, "data"))
+	t.Chdir(dir)
+
+	t.Run("config file", func(t *testing.T) {
+		got, _, err := resolveConfig(Flags{}, basePath)
+		if err != nil {
+			t.Fatalf("unexpected err: %v", err)
+		}
+		if want := filepath.Join(basePath, "sheets"); got.KeybPath != want {
+			t.Errorf("got %v, want %v", got.KeybPath, want)
+		}
+		if want := []string{filepath.Join(dir, "work"), "/src"}; !reflect.DeepEqual(got.TrustedDirs, want) {
+			t.Errorf("got %v, want %v", got.TrustedDirs, want)
+		}
+	})
+
+	t.Run("profile", func(t *testing.T) {
+		got, _, err := resolveConfig(Flags{Profile: "talk"}, basePath)
+		if err != nil {
+			t.Fatalf("unexpected err: %v", err)
+		}
+		if want := filepath.Join(basePath, "talk.yml"); got.KeybPath != want {
+			t.Errorf("got %v, want %v", got.KeybPath, want)
+		}
+	})
+
+	t.Run("flag", func(t *testing.T) {
+		got, _, err := resolveConfig(Flags{Values: map[string]string{"settings.keyb_path": "own.yml"}}, basePath)
+		if err != nil {
+			t.Fatalf("unexpected err: %v", err)
+		}
+		if want := filepath.Join(dir, "own.yml"); got.KeybPath != want {
+			t.Errorf("got %v, want %v", got.KeybPath, want)
+		}
+	})
+
+	t.Run("env", func(t *testing.T) {
+		t.Setenv("KEYB_TRUSTED_DIRS", "[src]")
+		got, _, err := resolveConfig(Flags{}, basePath)
+		if err != nil {
+			t.Fatalf("unexpected err: %v", err)
+		}
+		if want := []string{filepath.Join(dir, "src")}; !reflect.DeepEqual(got.TrustedDirs, want) {
+			t.Errorf("got %v, want %v", got.TrustedDirs, want)
+		}
+	})
+
+	t.Run("data dir", func(t *testing.T) {
+		writeFile(t, filepath.Join(dir, "data", "keyb", "git.yml"), "")
+		got, _, err := resolveConfig(Flags{Values: map[string]string{"settings.keyb_path": "git.yml"}}, basePath)
+		if err != nil {
+			t.Fatalf("unexpected err: %v", err)
+		}
+		if want := filepath.Join(dir, "data", "keyb", "git.yml"); got.KeybPath != want {
+			t.Errorf("got %v, want %v", got.KeybPath, want)
+		}
+	})
+}
@@ -0,0 +1,138 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"gopkg.in/yaml.v2"
+)
+
+// Project keyb files, read from each directory in order
+var projectKeybFiles = []string{".keyb", localConfigFile, defaultKeybFile}
+
+// Find project keyb files in dir and its parents up to the repository root,
+// outermost first. Outside a repository only dir is searched. A .keyb.yml with
+// config at its root is not a keyb file
+func FindProjectKeyb(dir string) []string {
+	dirs := []string{dir}
+	if root, ok := repoRoot(dir); ok {
+		dirs = nil
+		for d := dir; ; d = filepath.Dir(d) {
+			dirs = append([]string{d}, dirs...)
+			if d == root {
+				break
+			}
+		}
+	}
+
+	var res []string
+	for _, d := range dirs {
+		for _, name := range projectKeybFiles {
+			path := filepath.Join(d, name)
+			info, err := os.Stat(path)
+			if err != nil {
+				continue
+			}
+			if name == localConfigFile && !isKeybList(path) {
+				continue
+			}
+			if name == ".keyb" && !info.IsDir() {
+				continue
+			}
+			res = append(res, path)
+		}
+	}
+	return res
+}
+
+// Nearest directory containing .git
+func repoRoot(dir string) (string, bool) {
+	for {
+		if _, err := os.Stat(filepath.Join(dir, ".git")); err == nil {
+			return dir, true
+		}
+
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return "", false
+		}
+		dir = parent
+	}
+}
+
+// Whether the YAML file at path has a list at its root
+func isKeybList(path string) bool {
+	file, err := os.ReadFile(path)
+	if err != nil {
+		return false
+	}
+
+	var tree any
+	if err := yaml.Unmarshal(file, &tree); err != nil {
+		return false
+	}
+	_, ok := tree.([]any)
+	return ok
+}
+
+// Read project keyb files of the current directory. Files in directories
+// that are not in trusted_dirs are only read if trust returns true. Apps are
+// marked with the file they came from
+func LoadProjectKeyb(c *Config, trust func(dir string) bool) (Apps, error) {
+	wd, err := os.Getwd()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get working directory: %w", err)
+	}
+
+	cache := c.cache()
+
+	var (
+		res     Apps
+		decided = make(map[string]bool)
+	)
+	for _, path := range FindProjectKeyb(wd) {
+		// the user keyb file is not a project file
+		if path == c.keybFile {
+			continue
+		}
+
+		dir := filepath.Dir(path)
+		trusted, ok := decided[dir]
+		if !ok {
+			trusted = c.Trusted(dir) || (trust != nil && trust(dir))
+			decided[dir] = trusted
+		}
+		if !trusted {
+			continue
+		}
+
+		var apps Apps
+		if filepath.Base(path) == ".keyb" {
+			apps, err = readKeybDir(path, cache)
+		} else {
+			apps, err = readKeyb(path, cache)
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		for _, app := range apps {
+			app.Origin = path
+		}
+		res = append(res, apps...)
+	}
+	return res, nil
+}
+
+// Whether dir is, or is inside, a directory of trusted_dirs
+func (c *Config) Trusted(dir string) bool {
+	for _, t := range c.TrustedDirs {
+		t = filepath.Clean(ExpandPath(t, ""))
+		if rel, err := filepath.Rel(t, dir); err == nil && rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+			return true
+		}
+	}
+	return false
+}
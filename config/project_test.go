@@ -0,0 +1,132 @@
+package config
+
+import (
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestFindProjectKeyb(t *testing.T) {
+	dir := t.TempDir()
+	repo := filepath.Join(dir, "repo")
+	keyb := "- name: app\n  keybinds: []\n"
+
+	// above the repository root
+	writeFile(t, filepath.Join(dir, "keyb.yml"), keyb)
+
+	writeFile(t, filepath.Join(repo, ".git", "HEAD"), "")
+	writeFile(t, filepath.Join(repo, "keyb.yml"), keyb)
+	writeFile(t, filepath.Join(repo, ".keyb", "make.yml"), keyb)
+	writeFile(t, filepath.Join(repo, "sub", ".keyb.yml"), keyb)
+	writeFile(t, filepath.Join(repo, "sub", "dir", ".keyb.yml"), "settings:\n  title: config\n")
+
+	t.Run("repository", func(t *testing.T) {
+		got := FindProjectKeyb(filepath.Join(repo, "sub", "dir"))
+		want := []string{
+			filepath.Join(repo, ".keyb"),
+			filepath.Join(repo, "keyb.yml"),
+			filepath.Join(repo, "sub", ".keyb.yml"),
+		}
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("got %v, want %v", got, want)
+		}
+	})
+
+	t.Run("outside repository", func(t *testing.T) {
+		got := FindProjectKeyb(dir)
+		want := []string{filepath.Join(dir, "keyb.yml")}
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("got %v, want %v", got, want)
+		}
+	})
+}
+
+func TestLoadProjectKeyb(t *testing.T) {
+	dir := t.TempDir()
+	t.Setenv("XDG_CACHE_HOME", t.TempDir())
+
+	writeFile(t, filepath.Join(dir, ".git", "HEAD"), "")
+	writeFile(t, filepath.Join(dir, "keyb.yml"), "- name: make\n  keybinds: []\n")
+	writeFile(t, filepath.Join(dir, "sub", "keyb.yml"), "- name: tool\n  keybinds: []\n")
+	t.Chdir(filepath.Join(dir, "sub"))
+
+	var asked []string
+	trust := func(d string) bool {
+		asked = append(asked, d)
+		return false
+	}
+
+	c := &Config{Settings: Settings{TrustedDirs: []string{dir}}}
+	apps, err := LoadProjectKeyb(c, trust)
+	if err != nil {
+		t.Fatalf("unexpected err: %v", err)
+	}
+	var got []string
+	for _, app := range apps {
+		got = append(got, app.Heading())
+	}
+	want := []string{"make [" + filepath.Base(dir) + "]", "tool [sub]"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got %v, want %v", got, want)
+	}
+	if len(asked) != 0 {
+		t.Errorf("got prompts %v, want none", asked)
+	}
+
+	t.Run("untrusted", func(t *testing.T) {
+		c.TrustedDirs = []string{filepath.Join(dir, "sub")}
+		apps, err := LoadProjectKeyb(c, trust)
+		if err != nil {
+			t.Fatalf("unexpected err: %v", err)
+		}
+		if len(apps) != 1 || apps[0].Name != "tool" {
+			t.Errorf("got %v, want only tool", apps)
+		}
+		if want := []string{dir}; !reflect.DeepEqual(asked, want) {
+			t.Errorf("got prompts %v, want %v", asked, want)
+		}
+	})
+}
+
+func TestProjectCannotTrustItself(t *testing.T) {
+	dir := t.TempDir()
+	t.Setenv("XDG_CONFIG_HOME", filepath.Join(dir, "config"))
+	t.Setenv("XDG_CONFIG_DIRS", filepath.Join(dir, "etc"))
+	t.Setenv("XDG_CACHE_HOME", filepath.Join(dir, "cache"))
+
+	repo := filepath.Join(dir, "repo")
+	writeFile(t, filepath.Join(repo, ".git", "HEAD"), "")
+	writeFile(t, filepath.Join(repo, ".keyb.yml"), `
+settings:
+  title: repo
+  trusted_dirs: [.]
+  keyb_path: own.yml
+profiles:
+  evil:
+    settings:
+      trusted_dirs: [.]
+`)
+	writeFile(t, filepath.Join(repo, "keyb.yml"), "- name: evil\n  keybinds: []\n")
+	t.Chdir(repo)
+
+	for _, profile := range []string{"", "evil"} {
+		_, c, err := Parse(Flags{Profile: profile, ReadOnly: true})
+		if err != nil {
+			t.Fatalf("unexpected err: %v", err)
+		}
+		if c.Title != "repo" {
+			t.Errorf("got title %v, want other settings of .keyb.yml applied", c.Title)
+		}
+		if len(c.TrustedDirs) != 0 || c.KeybFile() == filepath.Join(repo, "own.yml") {
+			t.Errorf("got trusted_dirs %v and keyb file %v, want them ignored", c.TrustedDirs, c.KeybFile())
+		}
+
+		apps, err := LoadProjectKeyb(c, nil)
+		if err != nil {
+			t.Fatalf("unexpected err: %v", err)
+		}
+		if len(apps) != 0 {
+			t.Errorf("got %v, want untrusted repo skipped", apps)
+		}
+	}
+}
@@ -0,0 +1,209 @@
+package config
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+
+	"gopkg.in/yaml.v2"
+)
+
+const (
+	defaultTheme     = "default"
+	defaultThemesDir = "themes"
+)
+
+// ThemeColor is a color that may differ between light and dark terminal
+// backgrounds. It is given as a single color or as a map of light and dark
+// colors
+type ThemeColor struct {
+	Light string `yaml:"light" json:"light"`
+	Dark  string `yaml:"dark" json:"dark"`
+}
+
+func Adaptive(light, dark string) ThemeColor {
+	return ThemeColor{Light: light, Dark: dark}
+}
+
+func Solid(color string) ThemeColor {
+	return ThemeColor{Light: color, Dark: color}
+}
+
+func (c ThemeColor) Empty() bool {
+	return c.Light == "" && c.Dark == ""
+}
+
+func (c *ThemeColor) UnmarshalYAML(unmarshal func(interface{}) error) error {
+	var s string
+	if err := unmarshal(&s); err == nil {
+		*c = Solid(s)
+		return nil
+	}
+
+	type themeColor ThemeColor
+	return unmarshal((*themeColor)(c))
+}
+
+func (c *ThemeColor) UnmarshalJSON(data []byte) error {
+	var s string
+	if err := json.Unmarshal(data, &s); err == nil {
+		*c = Solid(s)
+		return nil
+	}
+
+	type themeColor ThemeColor
+	return json.Unmarshal(data, (*themeColor)(c))
+}
+
+type Theme struct {
+	PromptColor   ThemeColor `yaml:"prompt" json:"prompt"`
+	CursorFg      ThemeColor `yaml:"cursor_fg" json:"cursor_fg"`
+	CursorBg      ThemeColor `yaml:"cursor_bg" json:"cursor_bg"`
+	FilterFg      ThemeColor `yaml:"filter_fg" json:"filter_fg"`
+	FilterBg      ThemeColor `yaml:"filter_bg" json:"filter_bg"`
+	CounterFg     ThemeColor `yaml:"counter_fg" json:"counter_fg"`
+	CounterBg     ThemeColor `yaml:"counter_bg" json:"counter_bg"`
+	PlaceholderFg ThemeColor `yaml:"placeholder_fg" json:"placeholder_fg"`
+	PlaceholderBg ThemeColor `yaml:"placeholder_bg" json:"placeholder_bg"`
+	BorderColor   ThemeColor `yaml:"border_color" json:"border_color"`
+	HeadingFg     ThemeColor `yaml:"heading_fg" json:"heading_fg"`
+	HeadingBg     ThemeColor `yaml:"heading_bg" json:"heading_bg"`
+	RowFg         ThemeColor `yaml:"row_fg" json:"row_fg"`
+	RowBg         ThemeColor `yaml:"row_bg" json:"row_bg"`
+	KeyFg         ThemeColor `yaml:"key_fg" json:"key_fg"`
+	KeyBg         ThemeColor `yaml:"key_bg" json:"key_bg"`
+	PrefixFg      ThemeColor `yaml:"prefix_fg" json:"prefix_fg"`
+	PrefixBg      ThemeColor `yaml:"prefix_bg" json:"prefix_bg"`
+	TitleFg       ThemeColor `yaml:"title_fg" json:"title_fg"`
+	TitleBg       ThemeColor `yaml:"title_bg" json:"title_bg"`
+}
+
+var builtinThemes = map[string]Theme{
+	defaultTheme: {
+		FilterFg:      Solid("#FFA066"),
+		PlaceholderFg: Solid("240"),
+	},
+	"nord": {
+		PromptColor:   Adaptive("#5E81AC", "#88C0D0"),
+		CursorFg:      Adaptive("#ECEFF4", "#2E3440"),
+		CursorBg:      Adaptive("#5E81AC", "#88C0D0"),
+		FilterFg:      Adaptive("#BF616A", "#EBCB8B"),
+		CounterFg:     Adaptive("#4C566A", "#616E88"),
+		PlaceholderFg: Adaptive("#4C566A", "#616E88"),
+		BorderColor:   Adaptive("#81A1C1", "#4C566A"),
+		HeadingFg:     Adaptive("#5E81AC", "#81A1C1"),
+		RowFg:         Adaptive("#2E3440", "#D8DEE9"),
+		KeyFg:         Adaptive("#A3BE8C", "#A3BE8C"),
+		PrefixFg:      Adaptive("#B48EAD", "#B48EAD"),
+		TitleFg:       Adaptive("#2E3440", "#ECEFF4"),
+	},
+	"gruvbox": {
+		PromptColor:   Adaptive("#AF3A03", "#FE8019"),
+		CursorFg:      Adaptive("#FBF1C7", "#282828"),
+		CursorBg:      Adaptive("#458588", "#83A598"),
+		FilterFg:      Adaptive("#9D0006", "#FB4934"),
+		CounterFg:     Adaptive("#7C6F64", "#928374"),
+		PlaceholderFg: Adaptive("#7C6F64", "#928374"),
+		BorderColor:   Adaptive("#BDAE93", "#504945"),
+		HeadingFg:     Adaptive("#B57614", "#FABD2F"),
+		RowFg:         Adaptive("#3C3836", "#EBDBB2"),
+		KeyFg:         Adaptive("#79740E", "#B8BB26"),
+		PrefixFg:      Adaptive("#8F3F71", "#D3869B"),
+		TitleFg:       Adaptive("#AF3A03", "#FE8019"),
+	},
+	"solarized": {
+		PromptColor:   Solid("#268BD2"),
+		CursorFg:      Adaptive("#FDF6E3", "#002B36"),
+		CursorBg:      Adaptive("#268BD2", "#2AA198"),
+		FilterFg:      Solid("#CB4B16"),
+		CounterFg:     Adaptive("#93A1A1", "#586E75"),
+		PlaceholderFg: Adaptive("#93A1A1", "#586E75"),
+		BorderColor:   Adaptive("#93A1A1", "#586E75"),
+		HeadingFg:     Solid("#B58900"),
+		RowFg:         Adaptive("#586E75", "#93A1A1"),
+		KeyFg:         Solid("#859900"),
+		PrefixFg:      Solid("#6C71C4"),
+		TitleFg:       Solid("#268BD2"),
+	},
+}
+
+// Names of all built-in themes
+func BuiltinThemes() []string {
+	var names []string
+	for name := range builtinThemes {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Read theme by name. User theme files in the themes directory take
+// precedence over built-in themes and are merged with the default theme
+func LoadTheme(name, basePath string) (Theme, error) {
+	if name == "" {
+		name = defaultTheme
+	}
+
+	for _, ext := range []string{".yml", ".yaml", ".json"} {
+		path := filepath.Join(basePath, defaultThemesDir, name+ext)
+		file, err := os.ReadFile(path)
+		if err != nil {
+			if errors.Is(err, os.ErrNotExist) {
+				continue
+			}
+			return Theme{}, fmt.Errorf("failed to read theme file \"%s\": %w", path, err)
+		}
+
+		theme := builtinThemes[defaultTheme]
+		switch ext {
+		case ".json":
+			err = json.Unmarshal(file, &theme)
+		default:
+			err = yaml.Unmarshal(file, &theme)
+		}
+		if err != nil {
+			return Theme{}, fmt.Errorf("failed to unmarshal theme file \"%s\": %w", path, err)
+		}
+		return theme, nil
+	}
+
+	theme, ok := builtinThemes[name]
+	if !ok {
+		return Theme{}, fmt.Errorf("theme \"%s\" not found", name)
+	}
+	return theme, nil
+}
+
+// Apply colors set in the config over the theme
+func (t Theme) With(c Color) Theme {
+	set := func(dst *ThemeColor, color string) {
+		if color != "" {
+			*dst = Solid(color)
+		}
+	}
+
+	set(&t.PromptColor, c.PromptColor)
+	set(&t.CursorFg, c.CursorFg)
+	set(&t.CursorBg, c.CursorBg)
+	set(&t.FilterFg, c.FilterFg)
+	set(&t.FilterBg, c.FilterBg)
+	set(&t.CounterFg, c.CounterFg)
+	set(&t.CounterBg, c.CounterBg)
+	set(&t.PlaceholderFg, c.PlaceholderFg)
+	set(&t.PlaceholderBg, c.PlaceholderBg)
+	set(&t.BorderColor, c.BorderColor)
+	set(&t.HeadingFg, c.HeadingFg)
+	set(&t.HeadingBg, c.HeadingBg)
+	set(&t.RowFg, c.RowFg)
+	set(&t.RowBg, c.RowBg)
+	set(&t.KeyFg, c.KeyFg)
+	set(&t.KeyBg, c.KeyBg)
+	set(&t.PrefixFg, c.PrefixFg)
+	set(&t.PrefixBg, c.PrefixBg)
+	set(&t.TitleFg, c.TitleFg)
+	set(&t.TitleBg, c.TitleBg)
+	return t
+}
@@ -0,0 +1,107 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"gopkg.in/yaml.v2"
+)
+
+func TestLoadTheme(t *testing.T) {
+	t.Run("builtin", func(t *testing.T) {
+		got, err := LoadTheme("nord", t.TempDir())
+		if err != nil {
+			t.Fatalf("unexpected err: %v", err)
+		}
+		if !reflect.DeepEqual(got, builtinThemes["nord"]) {
+			t.Errorf("got %v, want %v", got, builtinThemes["nord"])
+		}
+	})
+
+	t.Run("empty name is default", func(t *testing.T) {
+		got, err := LoadTheme("", t.TempDir())
+		if err != nil {
+			t.Fatalf("unexpected err: %v", err)
+		}
+		if !reflect.DeepEqual(got, builtinThemes[defaultTheme]) {
+			t.Errorf("got %v, want %v", got, builtinThemes[defaultTheme])
+		}
+	})
+
+	t.Run("user theme", func(t *testing.T) {
+		dir := t.TempDir()
+		if err := os.MkdirAll(filepath.Join(dir, defaultThemesDir), 0744); err != nil {
+			t.Fatal(err)
+		}
+		file := "prompt: \"#268BD2\"\nheading_fg:\n  light: \"#B57614\"\n  dark: \"#FABD2F\"\n"
+		if err := os.WriteFile(filepath.Join(dir, defaultThemesDir, "mine.yml"), []byte(file), 0644); err != nil {
+			t.Fatal(err)
+		}
+
+		got, err := LoadTheme("mine", dir)
+		if err != nil {
+			t.Fatalf("unexpected err: %v", err)
+		}
+
+		want := builtinThemes[defaultTheme]
+		want.PromptColor = Solid("#268BD2")
+		want.HeadingFg = Adaptive("#B57614", "#FABD2F")
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("got %v, want %v", got, want)
+		}
+	})
+
+	t.Run("not found", func(t *testing.T) {
+		_, err := LoadTheme("foo", t.TempDir())
+		if err == nil {
+			t.Fatalf("expected err")
+		}
+		if err.Error() != `theme "foo" not found` {
+			t.Errorf("got %v, want %v", err.Error(), `theme "foo" not found`)
+		}
+	})
+}
+
+func TestThemeColorUnmarshal(t *testing.T) {
+	tests := []struct {
+		name string
+		yml  string
+		json string
+		want ThemeColor
+	}{
+		{"solid", `"#FFFFFF"`, `"#FFFFFF"`, Solid("#FFFFFF")},
+		{"adaptive", "{light: \"0\", dark: \"15\"}", `{"light": "0", "dark": "15"}`, Adaptive("0", "15")},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got ThemeColor
+			if err := yaml.Unmarshal([]byte(tt.yml), &got); err != nil {
+				t.Fatalf("unexpected err: %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("got %v, want %v", got, tt.want)
+			}
+
+			got = ThemeColor{}
+			if err := got.UnmarshalJSON([]byte(tt.json)); err != nil {
+				t.Fatalf("unexpected err: %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("got %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestThemeWith(t *testing.T) {
+	theme := builtinThemes["gruvbox"].With(Color{KeyFg: "#FFFFFF"})
+	if theme.KeyFg != Solid("#FFFFFF") {
+		t.Errorf("got %v, want %v", theme.KeyFg, Solid("#FFFFFF"))
+	}
+	if theme.HeadingFg != builtinThemes["gruvbox"].HeadingFg {
+		t.Errorf("got %v, want %v", theme.HeadingFg, builtinThemes["gruvbox"].HeadingFg)
+	}
+}
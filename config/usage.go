@@ -0,0 +1,161 @@
+package config
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+const defaultUsageFile = "usage.json"
+
+// Usage records pinned key bindings and how often they are used. Entries are
+// keyed by app and binding name so they survive edits to the keyb file
+type Usage struct {
+	path    string
+	entries []*UsageEntry
+	index   map[usageKey]*UsageEntry
+	now     func() time.Time
+}
+
+type UsageEntry struct {
+	App    string    `json:"app"`
+	Name   string    `json:"name"`
+	Count  int       `json:"count,omitempty"`
+	Last   time.Time `json:"last"`
+	Pinned bool      `json:"pinned,omitempty"`
+}
+
+type usageKey struct {
+	app  string
+	name string
+}
+
+// Load usage store from the default state directory
+func LoadUsage() (*Usage, error) {
+	xdgStateDir, err := getXDGStateDir()
+	if err != nil {
+		return nil, err
+	}
+	return NewUsage(filepath.Join(xdgStateDir, defaultConfigDir, defaultUsageFile))
+}
+
+// Read usage store at path. A missing file results in an empty store and an
+// empty path keeps the store in memory only
+func NewUsage(path string) (*Usage, error) {
+	u := &Usage{
+		path:  path,
+		index: make(map[usageKey]*UsageEntry),
+		now:   time.Now,
+	}
+	if path == "" {
+		return u, nil
+	}
+
+	file, err := os.ReadFile(path)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return u, nil
+		}
+		return nil, fmt.Errorf("failed to read usage file: %w", err)
+	}
+
+	var entries []*UsageEntry
+	if err := json.Unmarshal(file, &entries); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal usage file: %w", err)
+	}
+	for _, e := range entries {
+		u.entries = append(u.entries, e)
+		u.index[usageKey{e.App, e.Name}] = e
+	}
+	return u, nil
+}
+
+// Keep changes in memory only
+func (u *Usage) SetReadOnly() {
+	u.path = ""
+}
+
+func (u *Usage) get(app, name string) *UsageEntry {
+	k := usageKey{app, name}
+	e, ok := u.index[k]
+	if !ok {
+		e = &UsageEntry{App: app, Name: name}
+		u.entries = append(u.entries, e)
+		u.index[k] = e
+	}
+	return e
+}
+
+// Record a use of the key binding
+func (u *Usage) Record(app, name string) error {
+	e := u.get(app, name)
+	e.Count++
+	e.Last = u.now()
+	return u.write()
+}
+
+// Pin or unpin the key binding, returning its new state
+func (u *Usage) TogglePin(app, name string) (bool, error) {
+	e := u.get(app, name)
+	e.Pinned = !e.Pinned
+	return e.Pinned, u.write()
+}
+
+func (u *Usage) IsPinned(app, name string) bool {
+	e, ok := u.index[usageKey{app, name}]
+	return ok && e.Pinned
+}
+
+// Frecency score of the key binding. Uses are weighted by how recently the
+// binding was last used
+func (u *Usage) Score(app, name string) int {
+	e, ok := u.index[usageKey{app, name}]
+	if !ok || e.Count == 0 {
+		return 0
+	}
+
+	var weight int
+	switch age := u.now().Sub(e.Last); {
+	case age < 4*24*time.Hour:
+		weight = 100
+	case age < 14*24*time.Hour:
+		weight = 70
+	case age < 31*24*time.Hour:
+		weight = 50
+	case age < 90*24*time.Hour:
+		weight = 30
+	default:
+		weight = 10
+	}
+	return e.Count * weight
+}
+
+func (u *Usage) write() error {
+	if u.path == "" {
+		return nil
+	}
+
+	// drop entries that carry no information
+	var entries []*UsageEntry
+	for _, e := range u.entries {
+		if e.Count > 0 || e.Pinned {
+			entries = append(entries, e)
+		}
+	}
+
+	data, err := json.Marshal(entries)
+	if err != nil {
+		return fmt.Errorf("failed to marshal usage: %w", err)
+	}
+
+	if err := os.MkdirAll(filepath.Dir(u.path), 0744); err != nil {
+		return fmt.Errorf("failed to create state dir: %w", err)
+	}
+	if err := os.WriteFile(u.path, data, 0644); err != nil {
+		return fmt.Errorf("failed to write usage file: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,68 @@
+package config
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestUsage(t *testing.T) {
+	t.Run("pin and reload", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "usage.json")
+
+		u, err := NewUsage(path)
+		if err != nil {
+			t.Fatalf("unexpected err: %v", err)
+		}
+		if _, err := u.TogglePin("tmux", "new window"); err != nil {
+			t.Fatalf("unexpected err: %v", err)
+		}
+		if err := u.Record("tmux", "split"); err != nil {
+			t.Fatalf("unexpected err: %v", err)
+		}
+
+		got, err := NewUsage(path)
+		if err != nil {
+			t.Fatalf("unexpected err: %v", err)
+		}
+		if !got.IsPinned("tmux", "new window") {
+			t.Errorf("want tmux/new window pinned")
+		}
+		if got.IsPinned("tmux", "split") {
+			t.Errorf("want tmux/split unpinned")
+		}
+		if got.Score("tmux", "split") == 0 {
+			t.Errorf("want tmux/split to be scored")
+		}
+	})
+
+	t.Run("score", func(t *testing.T) {
+		now := time.Date(2024, 1, 31, 0, 0, 0, 0, time.UTC)
+		u, err := NewUsage("")
+		if err != nil {
+			t.Fatalf("unexpected err: %v", err)
+		}
+
+		u.now = func() time.Time { return now.AddDate(0, 0, -20) }
+		for i := 0; i < 3; i++ {
+			u.Record("vim", "old")
+		}
+		u.now = func() time.Time { return now.AddDate(0, 0, -1) }
+		u.Record("vim", "recent")
+		u.now = func() time.Time { return now }
+
+		tests := []struct {
+			name string
+			want int
+		}{
+			{"old", 150},
+			{"recent", 100},
+			{"unused", 0},
+		}
+		for _, tt := range tests {
+			if got := u.Score("vim", tt.name); got != tt.want {
+				t.Errorf("%s: got %d, want %d", tt.name, got, tt.want)
+			}
+		}
+	})
+}
@@ -0,0 +1,12 @@
+//go:build !unix
+
+package config
+
+import "os"
+
+// Whether path, or the nearest existing directory it would be created in, can
+// be written to. Only the read-only attribute is checked
+func writable(path string) bool {
+	info, err := os.Stat(existing(path))
+	return err == nil && info.Mode().Perm()&0200 != 0
+}
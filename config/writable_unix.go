@@ -0,0 +1,11 @@
+//go:build unix
+
+package config
+
+import "golang.org/x/sys/unix"
+
+// Whether path, or the nearest existing directory it would be created in, can
+// be written to
+func writable(path string) bool {
+	return unix.Access(existing(path), unix.W_OK) == nil
+}
@@ -0,0 +1,92 @@
+package config
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+const lockDir = "locks"
+
+// Short name derived from path, used to key files of path in cache and state
+// dirs
+func pathKey(path string) string {
+	sum := sha256.Sum256([]byte(path))
+	return hex.EncodeToString(sum[:16])
+}
+
+// Lock keyb file at path against writes from other keyb processes until
+// unlock is called. Lock files are kept in the state dir so nothing is added
+// next to the keyb file
+func lockKeyb(path string) (unlock func(), err error) {
+	xdgStateDir, err := getXDGStateDir()
+	if err != nil {
+		return nil, err
+	}
+
+	path, err = filepath.Abs(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve keyb file: %w", err)
+	}
+
+	dir := filepath.Join(xdgStateDir, defaultConfigDir, lockDir)
+	if err := os.MkdirAll(dir, 0744); err != nil {
+		return nil, fmt.Errorf("failed to create lock dir: %w", err)
+	}
+
+	unlock, err = lockFile(filepath.Join(dir, pathKey(path)+".lock"))
+	if err != nil {
+		return nil, fmt.Errorf("failed to lock keyb file: %w", err)
+	}
+	return unlock, nil
+}
+
+// Replace keyb file at path with data, keeping the previous version as a
+// backup. The caller must hold the lock of path
+func writeKeyb(path string, data []byte) error {
+	backups, err := LoadBackups(path)
+	if err != nil {
+		return err
+	}
+	return backups.write(data)
+}
+
+// Write data to a temporary file next to path and rename it over path, so
+// path is never left partially written. Symlinks are followed and the mode of
+// an existing file is kept
+func writeAtomic(path string, data []byte, perm os.FileMode) error {
+	if target, err := filepath.EvalSymlinks(path); err == nil {
+		path = target
+	} else if !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+	if info, err := os.Stat(path); err == nil {
+		perm = info.Mode().Perm()
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(path), "."+filepath.Base(path)+".*")
+	if err != nil {
+		return err
+	}
+	// no-op once renamed
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Sync(); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	if err := os.Chmod(tmp.Name(), perm); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
@@ -1,12 +1,18 @@
 package main
 
 import (
+	"bufio"
 	"flag"
 	"fmt"
 	"log"
 	"os"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
 
 	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/x/term"
 	"github.com/kencx/keyb/config"
 	"github.com/kencx/keyb/output"
 	"github.com/kencx/keyb/ui"
@@ -20,11 +26,24 @@ const (
     -e, --export    Export to file [yaml, json]
     -k, --key       Key bindings at custom path
     -c, --config    Config file at custom path
+    -s, --saved     Start with saved query
+    --profile       Config profile to use
+    --read-only     Write nothing to disk
+    --height        Height in lines or percent, drawn inline [e.g. 20, 40%]
+    --columns       Number of columns to print or export
+    --width         Width to fit columns into when printing or exporting
     -v, --version   Version info
     -h, --help	    Show help
 
   Commands:
+    init            Create default config and keyb files
     a, add          Add keybind to keyb file
+    rm              Remove keybind from keyb file
+    undo            Undo last change to keyb file
+    backups list    List backups of keyb file
+    restore <n>     Restore backup n of keyb file
+    cache clear     Remove cached keyb files
+    config show     Show effective config
 `
 
 	addHelp = `usage: keyb [-k file] add [app; name; key]
@@ -34,6 +53,19 @@ const (
     -b, --binding  Key binding
     -p, --prefix   Ignore prefix
 `
+
+	rmHelp = `usage: keyb [-k file] rm [app; name]
+
+  Options:
+    -k, --key      Key bindings file at custom path
+    -b, --binding  Key binding
+`
+
+	configHelp = `usage: keyb config show [--origin]
+
+  Options:
+    --origin       Show layer each setting came from
+`
 )
 
 var version string
@@ -47,9 +79,19 @@ func main() {
 		exportFile string
 		keybFile   string
 		configFile string
+		savedQuery string
+		profile    string
+		readOnly   bool
+		height     string
+		columns    int
+		width      int
 
 		addBind   string
 		addPrefix bool
+
+		rmBind string
+
+		showOrigin bool
 	)
 
 	shortVersion := flag.Bool("v", false, "version information")
@@ -67,12 +109,29 @@ func main() {
 	flag.StringVar(&configFile, "c", "", "config file")
 	flag.StringVar(&configFile, "config", "", "config file")
 
+	flag.StringVar(&savedQuery, "s", "", "saved query")
+	flag.StringVar(&savedQuery, "saved", "", "saved query")
+
+	flag.StringVar(&profile, "profile", "", "config profile")
+	flag.BoolVar(&readOnly, "read-only", false, "read-only mode")
+
+	flag.StringVar(&height, "height", "", "window height")
+	flag.IntVar(&columns, "columns", 0, "number of columns")
+	flag.IntVar(&width, "width", 0, "width of columns")
+
 	addCmd := flag.NewFlagSet("add", flag.ExitOnError)
 	addCmd.StringVar(&addBind, "b", "", "keybind")
 	addCmd.StringVar(&addBind, "binding", "", "keybind")
 	addCmd.BoolVar(&addPrefix, "p", false, "prefix")
 	addCmd.BoolVar(&addPrefix, "prefix", false, "prefix")
 
+	rmCmd := flag.NewFlagSet("rm", flag.ExitOnError)
+	rmCmd.StringVar(&rmBind, "b", "", "keybind")
+	rmCmd.StringVar(&rmBind, "binding", "", "keybind")
+
+	configCmd := flag.NewFlagSet("config", flag.ExitOnError)
+	configCmd.BoolVar(&showOrigin, "origin", false, "show origin")
+
 	flag.Usage = func() { os.Stdout.Write([]byte(help)) }
 	flag.Parse()
 
@@ -81,38 +140,177 @@ func main() {
 		os.Exit(0)
 	}
 
-	keys, cfg, err := config.Parse(configFile, keybFile)
+	// flags override all other config layers
+	flags := config.Flags{
+		Config:   configFile,
+		Profile:  profile,
+		Values:   make(map[string]string),
+		ReadOnly: readOnly,
+	}
+
+	// flags given explicitly apply even if empty, e.g. --height "" for full
+	// screen
+	flag.Visit(func(f *flag.Flag) {
+		switch f.Name {
+		case "k", "key":
+			flags.Values["settings.keyb_path"] = keybFile
+		case "height":
+			flags.Values["settings.height"] = height
+		}
+	})
+
+	keys, cfg, err := config.Parse(flags)
 	if err != nil {
 		log.Fatal(err)
 	}
 
 	args := flag.Args()
-	if len(args) > 1 {
+	if len(args) > 0 {
 		switch args[0] {
+		case "init":
+			if flags.ReadOnly {
+				log.Fatal("cannot init in read-only mode")
+			}
+			created, err := config.Init()
+			if err != nil {
+				log.Fatal(err)
+			}
+			for _, path := range created {
+				fmt.Printf("created %s\n", path)
+			}
+			os.Exit(0)
 		case "add", "a":
 			addCmd.Usage = func() { os.Stdout.Write([]byte(addHelp)) }
 			addCmd.Parse(args[1:])
 
-			var addFile string
-			if keybFile != "" {
-				// use flag -k path
-				addFile = keybFile
-			} else {
-				// use default path in config
-				addFile = cfg.KeybPath
+			if cfg.ReadOnly() {
+				log.Fatal("cannot add in read-only mode")
 			}
+
+			// -k is resolved with the config
+			addFile := cfg.KeybFile()
 			if err := config.AddEntry(addFile, addBind, addPrefix); err != nil {
 				log.Fatal(err)
 			}
 			fmt.Printf("%s added to %s", addBind, addFile)
 			os.Exit(0)
+		case "rm":
+			rmCmd.Usage = func() { os.Stdout.Write([]byte(rmHelp)) }
+			rmCmd.Parse(args[1:])
+
+			if cfg.ReadOnly() {
+				log.Fatal("cannot remove in read-only mode")
+			}
+			if err := config.RemoveEntry(cfg.KeybFile(), rmBind); err != nil {
+				log.Fatal(err)
+			}
+			fmt.Printf("%s removed from %s\n", rmBind, cfg.KeybFile())
+			os.Exit(0)
+		case "undo":
+			if cfg.ReadOnly() {
+				log.Fatal("cannot undo in read-only mode")
+			}
+			op, err := config.UndoEntry(cfg.KeybFile())
+			if err != nil {
+				log.Fatal(err)
+			}
+			fmt.Printf("undid %s\n", op)
+			os.Exit(0)
+		case "backups":
+			if len(args) < 2 || args[1] != "list" {
+				fmt.Print(help)
+				os.Exit(1)
+			}
+			backups, err := config.LoadBackups(cfg.KeybFile())
+			if err != nil {
+				log.Fatal(err)
+			}
+			list, err := backups.List()
+			if err != nil {
+				log.Fatal(err)
+			}
+
+			tw := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+			for i, b := range list {
+				fmt.Fprintf(tw, "%d\t%s\t%d bytes\n", i+1, b.Time.Format(time.DateTime), b.Size)
+			}
+			tw.Flush()
+			os.Exit(0)
+		case "restore":
+			if len(args) < 2 {
+				fmt.Print(help)
+				os.Exit(1)
+			}
+			n, err := strconv.Atoi(args[1])
+			if err != nil {
+				log.Fatalf("invalid backup \"%s\"", args[1])
+			}
+			if cfg.ReadOnly() {
+				log.Fatal("cannot restore in read-only mode")
+			}
+
+			backups, err := config.LoadBackups(cfg.KeybFile())
+			if err != nil {
+				log.Fatal(err)
+			}
+			if err := backups.Restore(n); err != nil {
+				log.Fatal(err)
+			}
+			fmt.Printf("restored backup %d to %s\n", n, cfg.KeybFile())
+			os.Exit(0)
+		case "cache":
+			if len(args) < 2 || args[1] != "clear" {
+				fmt.Print(help)
+				os.Exit(1)
+			}
+			cache, err := config.LoadCache()
+			if err != nil {
+				log.Fatal(err)
+			}
+			if err := cache.Clear(); err != nil {
+				log.Fatal(err)
+			}
+			os.Exit(0)
+		case "config":
+			configCmd.Usage = func() { os.Stdout.Write([]byte(configHelp)) }
+			if len(args) < 2 || args[1] != "show" {
+				configCmd.Usage()
+				os.Exit(1)
+			}
+			configCmd.Parse(args[2:])
+
+			if err := cfg.Show(os.Stdout, showOrigin); err != nil {
+				log.Fatal(err)
+			}
+			os.Exit(0)
 		default:
 			fmt.Print(help)
 			os.Exit(1)
 		}
 	}
 
+	// printing and exporting run in scripts, so they only read trusted_dirs
+	// without asking
+	trust := promptTrust
+	if stdout || exportFile != "" {
+		trust = nil
+	}
+	project, err := config.LoadProjectKeyb(cfg, trust)
+	if err != nil {
+		log.Fatal(err)
+	}
+	keys = append(keys, project...)
+
+	windowHeight, err := config.ParseHeight(cfg.Height)
+	if err != nil {
+		log.Fatal(err)
+	}
+
 	m := ui.NewModel(keys, cfg)
+	m.SetHeight(windowHeight)
+	if columns > 0 || width > 0 {
+		m.List.SetPrintLayout(columns, width)
+	}
 
 	if stdout {
 		if err := output.ToStdout(m); err != nil {
@@ -127,17 +325,77 @@ func main() {
 		os.Exit(0)
 	}
 
+	if savedQuery != "" {
+		query, ok := cfg.Saved[savedQuery]
+		if !ok {
+			log.Fatalf("saved query \"%s\" not found", savedQuery)
+		}
+		m.List.ApplyQuery(query)
+	}
+
+	// history and usage are best effort, so keyb starts with empty ones kept
+	// in memory if they cannot be read
+	history, err := config.LoadHistory(cfg.HistorySize)
+	if err != nil {
+		log.Printf("%v, history is not saved", err)
+		history, _ = config.NewHistory("", cfg.HistorySize)
+	}
+	if cfg.ReadOnly() {
+		history.SetReadOnly()
+	}
+	m.List.SetHistory(history)
+
+	usage, err := config.LoadUsage()
+	if err != nil {
+		log.Printf("%v, favourites are not saved", err)
+		usage, _ = config.NewUsage("")
+	}
+	if cfg.ReadOnly() {
+		usage.SetReadOnly()
+	}
+	m.List.SetUsage(usage)
+
+	if !cfg.ReadOnly() {
+		// keyb directories cannot be edited and are only viewed
+		if editor, err := config.LoadEditor(cfg.KeybFile()); err == nil {
+			m.SetEditor(editor)
+		}
+	}
+
 	if err := start(m); err != nil {
 		log.Fatal(err)
 	}
+
+	// edits made just before quitting
+	if err := m.Flush(); err != nil {
+		log.Fatal(err)
+	}
 }
 
 func start(m *ui.Model) error {
 
-	p := tea.NewProgram(m, tea.WithMouseCellMotion(), tea.WithAltScreen())
+	opts := []tea.ProgramOption{tea.WithMouseCellMotion()}
+	if !m.Inline() {
+		opts = append(opts, tea.WithAltScreen())
+	}
+
+	p := tea.NewProgram(m, opts...)
 
 	if _, err := p.Run(); err != nil {
 		return fmt.Errorf("failed to start: %w", err)
 	}
 	return nil
 }
+
+// Ask whether to read project keyb files in dir. Only asked when stdin is a
+// terminal, otherwise untrusted directories are skipped
+func promptTrust(dir string) bool {
+	if !term.IsTerminal(os.Stdin.Fd()) {
+		return false
+	}
+
+	fmt.Fprintf(os.Stderr, "keyb: read key bindings in %s? Add it to trusted_dirs to skip this prompt [y/N] ", dir)
+	answer, _ := bufio.NewReader(os.Stdin).ReadString('\n')
+	answer = strings.ToLower(strings.TrimSpace(answer))
+	return answer == "y" || answer == "yes"
+}
@@ -6,6 +6,7 @@ import (
 	"os"
 	"path/filepath"
 
+	"github.com/kencx/keyb/config"
 	"github.com/kencx/keyb/ui"
 	"gopkg.in/yaml.v2"
 )
@@ -16,7 +17,7 @@ func ToFile(m *ui.Model, path string) error {
 		err    error
 	)
 
-	path = os.ExpandEnv(path)
+	path = config.ExpandPath(path, "")
 	ext := filepath.Ext(path)
 
 	switch ext {
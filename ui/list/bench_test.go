@@ -0,0 +1,96 @@
+package list
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/charmbracelet/bubbles/cursor"
+	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/kencx/keyb/config"
+	"github.com/kencx/keyb/ui/table"
+)
+
+var benchSizes = []int{1000, 10000, 50000}
+
+func benchModel(n int) Model {
+	rows := make([]*table.Row, 0, n+n/20)
+	for i := range n {
+		heading := fmt.Sprintf("app %d", i/20)
+		if i%20 == 0 {
+			rows = append(rows, table.NewHeading(heading))
+		}
+		rows = append(rows, table.NewRow(fmt.Sprintf("command number %d", i), fmt.Sprintf("ctrl+%d", i%10), "", heading))
+	}
+
+	c := config.DefaultConfig.Copy()
+	m := New(table.New(rows), c)
+	m, _ = m.Update(tea.WindowSizeMsg{Width: 100, Height: 50})
+	return m
+}
+
+func keyMsg(s string) tea.KeyMsg {
+	return tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune(s)}
+}
+
+// Update m with msg and run the returned commands, feeding their messages
+// back as the program would
+func updateAll(m Model, msg tea.Msg) Model {
+	m, cmd := m.Update(msg)
+	return runCmd(m, cmd)
+}
+
+func runCmd(m Model, cmd tea.Cmd) Model {
+	if cmd == nil {
+		return m
+	}
+
+	switch msg := cmd().(type) {
+	case nil:
+	case tea.BatchMsg:
+		for _, cmd := range msg {
+			m = runCmd(m, cmd)
+		}
+	default:
+		m = updateAll(m, msg)
+	}
+	return m
+}
+
+// Moving the cursor and drawing the view stays flat as the row count grows
+func BenchmarkCursor(b *testing.B) {
+	for _, n := range benchSizes {
+		b.Run(fmt.Sprint(n), func(b *testing.B) {
+			m := benchModel(n)
+			down := keyMsg("j")
+
+			b.ResetTimer()
+			for range b.N {
+				m, _ = m.Update(down)
+				m.View()
+			}
+		})
+	}
+}
+
+// Typing a query that narrows the rows to a few matches, then drawing the
+// view
+func BenchmarkTyping(b *testing.B) {
+	for _, n := range benchSizes {
+		b.Run(fmt.Sprint(n), func(b *testing.B) {
+			m := benchModel(n)
+			m.startSearch()
+			// blink commands sleep until the next blink
+			m.searchBar.Cursor.SetMode(cursor.CursorStatic)
+			m = updateAll(m, keyMsg("number 99"))
+			backspace := tea.KeyMsg{Type: tea.KeyBackspace}
+
+			b.ResetTimer()
+			for range b.N {
+				m = updateAll(m, keyMsg("9"))
+				m.View()
+				m = updateAll(m, backspace)
+			}
+		})
+	}
+}
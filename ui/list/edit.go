@@ -0,0 +1,82 @@
+package list
+
+import (
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/kencx/keyb/ui/table"
+)
+
+// DeleteMsg requests the key binding of a row to be removed from the keyb file
+type DeleteMsg struct {
+	Heading string
+	Name    string
+	Key     string
+}
+
+// UndoMsg requests the last edit of the keyb file to be undone
+type UndoMsg struct{}
+
+// RedoMsg requests the last undone edit of the keyb file to be redone
+type RedoMsg struct{}
+
+// Request the key binding under the cursor to be deleted
+func (m *Model) deleteRow() tea.Cmd {
+	row := m.currentRow()
+	if row == nil || row.IsHeading || row.Text == "" {
+		return nil
+	}
+
+	msg := DeleteMsg{Heading: row.Heading, Name: row.Text, Key: row.Key}
+	return func() tea.Msg {
+		return msg
+	}
+}
+
+func undo() tea.Msg {
+	return UndoMsg{}
+}
+
+func redo() tea.Msg {
+	return RedoMsg{}
+}
+
+// Show msg in the status line until the next key press
+func (m *Model) SetMessage(msg string) {
+	m.message = msg
+}
+
+// Replace the table, e.g. after the keyb file was edited. Folded sections,
+// the cursor position and the current search are kept
+func (m *Model) SetTable(t *table.Model) tea.Cmd {
+	folded := make(map[string]bool)
+	for _, h := range m.table.GetHeadings() {
+		folded[h.Text] = h.Folded
+	}
+	favFolded := len(m.table.Rows) > 0 && m.table.Rows[0] != nil &&
+		m.table.Rows[0].Virtual && m.table.Rows[0].Folded
+
+	t.SepWidth = m.table.SepWidth
+	t.MaxWidth = m.table.MaxWidth
+	t.Wrap = m.table.Wrap
+	t.Grid = m.table.Grid
+	t.Columns = m.table.Columns
+	m.styleRows(t)
+
+	m.table = t
+	for _, h := range t.GetHeadings() {
+		t.SetFolded(h, folded[h.Text])
+	}
+	m.syncFavourites()
+	if favFolded && len(t.Rows) > 0 && t.Rows[0].Virtual {
+		t.SetFolded(t.Rows[0], true)
+	}
+
+	var cmd tea.Cmd
+	if m.filterState == filtering && m.searchBar.Value() != "" {
+		cmd = m.filterRows()
+	}
+
+	m.maxRows = m.currentTable().LineCount
+	m.cursor = max(0, min(m.cursor, m.maxRows-1))
+	m.visibleRows()
+	return cmd
+}
@@ -0,0 +1,107 @@
+package list
+
+import (
+	"github.com/atotto/clipboard"
+	"github.com/kencx/keyb/config"
+	"github.com/kencx/keyb/ui/table"
+)
+
+const favouritesHeading = "Favourites"
+
+func (m *Model) SetUsage(u *config.Usage) {
+	m.usage = u
+	m.syncFavourites()
+	m.visibleRows()
+}
+
+// Rebuild the virtual Favourites section at the top of the table
+func (m *Model) syncFavourites() {
+	folded := len(m.table.Rows) > 0 && m.table.Rows[0] != nil &&
+		m.table.Rows[0].Virtual && m.table.Rows[0].Folded
+
+	m.table.RemoveVirtual()
+	if m.usage == nil {
+		return
+	}
+
+	var rows []*table.Row
+	for _, row := range m.table.Rows {
+		if row != nil && !row.IsHeading && m.usage.IsPinned(row.Heading, row.Text) {
+			// copy to keep selection and filtering state separate
+			fav := *row
+			fav.Virtual = true
+			fav.Hidden = false
+			rows = append(rows, &fav)
+		}
+	}
+	if len(rows) == 0 {
+		return
+	}
+
+	heading := table.NewHeading(favouritesHeading)
+	heading.Virtual = true
+	heading.Styles = m.rowStyles
+	m.table.Prepend(append([]*table.Row{heading}, rows...)...)
+	m.table.SetFolded(heading, folded)
+}
+
+// Row under the cursor
+func (m *Model) currentRow() *table.Row {
+	rows := m.currentTable().VisibleRows()
+	if m.cursor < 0 || m.cursor >= len(rows) {
+		return nil
+	}
+	return rows[m.cursor]
+}
+
+// Pin or unpin the row under the cursor
+func (m *Model) togglePin() {
+	row := m.currentRow()
+	if m.usage == nil || row == nil || row.IsHeading {
+		return
+	}
+
+	pinned, err := m.usage.TogglePin(row.Heading, row.Text)
+	if err != nil {
+		m.message = "failed to save pin"
+	} else if pinned {
+		m.message = "pinned"
+	} else {
+		m.message = "unpinned"
+	}
+
+	m.syncFavourites()
+
+	// keep cursor on the same row when the unfiltered table changes
+	if m.filteredTable.Empty() {
+		if i := m.table.IndexOf(row); i >= 0 {
+			m.cursor = i
+		}
+	}
+}
+
+// Record a use of the row under the cursor
+func (m *Model) selectRow() {
+	row := m.currentRow()
+	if m.usage == nil || row == nil || row.IsHeading {
+		return
+	}
+	// usage is best effort and must not interrupt the session
+	_ = m.usage.Record(row.Heading, row.Text)
+}
+
+// Copy key of the row under the cursor to the clipboard. Copying selects the
+// row, even if the clipboard is unavailable
+func (m *Model) copyRow() {
+	row := m.currentRow()
+	if row == nil || row.IsHeading || row.Key == "" {
+		return
+	}
+	m.selectRow()
+
+	if err := clipboard.WriteAll(row.Key); err != nil {
+		m.message = "failed to copy"
+		return
+	}
+	m.message = "copied"
+}
@@ -0,0 +1,171 @@
+package list
+
+import (
+	"context"
+	"sort"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/kencx/keyb/ui/table"
+	"github.com/sahilm/fuzzy"
+)
+
+const (
+	headingPrefix = "h:"
+
+	// tables with fewer rows are filtered synchronously
+	asyncFilterRows = 5000
+
+	// rows matched between checks for cancellation
+	filterChunk = 2048
+)
+
+// Matches of a query, tagged with the generation of the query. Results of
+// older generations are stale and dropped
+type filterMsg struct {
+	generation int
+	headings   bool
+	rows       []*table.Row
+	matches    fuzzy.Matches
+}
+
+// Filter rows with the current search input. Large tables are filtered in the
+// returned command, with results delivered as a filterMsg
+func (m *Model) filterRows() tea.Cmd {
+	m.cancelFilter()
+
+	query := m.searchBar.Value()
+	if query == "" {
+		// reset if search input is empty regardless of filterState
+		m.Reset()
+
+		// remain in filtering state
+		// until user explicitly returns to Normal mode
+		m.filterState = filtering
+		return nil
+	}
+
+	msg := filterMsg{generation: m.generation}
+	var targets []string
+	if strings.HasPrefix(query, headingPrefix) {
+		query = strings.TrimSpace(strings.TrimPrefix(query, headingPrefix))
+		msg.headings = true
+		msg.rows = m.table.GetHeadings()
+		targets = m.table.GetPlainHeadings()
+	} else {
+		msg.rows = m.table.GetRowsWithoutHeadings()
+		targets = m.table.GetPlainRowsWithoutHeadings()
+	}
+
+	if len(targets) < m.asyncRows {
+		msg.matches = filter(query, targets)
+		m.showMatches(msg)
+		return nil
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	m.cancel = cancel
+	m.searching = true
+
+	// targets are cached by the table and not modified while filtering
+	return func() tea.Msg {
+		msg.matches = find(ctx, query, targets)
+		if ctx.Err() != nil {
+			return nil
+		}
+		return msg
+	}
+}
+
+// Cancel the query in flight, if any, and drop its results
+func (m *Model) cancelFilter() {
+	if m.cancel != nil {
+		m.cancel()
+		m.cancel = nil
+	}
+	m.searching = false
+	m.generation++
+}
+
+func filter(term string, target []string) fuzzy.Matches {
+	return find(context.Background(), term, target)
+}
+
+// Fuzzy find term in target in chunks, stopping early if ctx is cancelled
+func find(ctx context.Context, term string, target []string) fuzzy.Matches {
+	var matches fuzzy.Matches
+	for start := 0; start < len(target); start += filterChunk {
+		if ctx.Err() != nil {
+			return nil
+		}
+
+		end := min(start+filterChunk, len(target))
+		for _, match := range fuzzy.FindNoSort(term, target[start:end]) {
+			match.Index += start
+			matches = append(matches, match)
+		}
+	}
+	sort.Stable(matches)
+	return matches
+}
+
+// Present matches of the current query as filtered rows
+func (m *Model) showMatches(msg filterMsg) {
+	if msg.generation != m.generation {
+		return
+	}
+	m.cancel = nil
+	m.searching = false
+
+	m.filteredTable.Reset()
+	switch {
+	case len(msg.matches) == 0:
+		m.filteredTable.AppendRow(table.EmptyRow())
+	case msg.headings:
+		matchHeadings(m, msg.rows, msg.matches)
+	default:
+		matchRows(m, msg.rows, msg.matches)
+	}
+}
+
+func matchHeadings(m *Model, headings []*table.Row, matches fuzzy.Matches) {
+	var hlMatches []*table.Row
+	for _, match := range matches {
+		// copy as filtering is ephemeral
+		heading := headings[match.Index].Copy()
+		heading.IsFiltered = true
+		heading.MatchedIndex = match.MatchedIndexes
+
+		hlMatches = append(hlMatches, &heading)
+		for _, r := range m.table.GetAllRowsofHeading(heading.Text) {
+			row := *r
+			row.Hidden = false
+			hlMatches = append(hlMatches, &row)
+		}
+	}
+	m.filteredTable.AppendRows(hlMatches...)
+}
+
+func matchRows(m *Model, rows []*table.Row, matches fuzzy.Matches) {
+	// rank frequently and recently used rows first among equally good matches
+	if m.frecency && m.usage != nil {
+		sort.SliceStable(matches, func(i, j int) bool {
+			if matches[i].Score != matches[j].Score {
+				return matches[i].Score > matches[j].Score
+			}
+			a, b := rows[matches[i].Index], rows[matches[j].Index]
+			return m.usage.Score(a.Heading, a.Text) > m.usage.Score(b.Heading, b.Text)
+		})
+	}
+
+	// copy as filtering is ephemeral
+	copies := make([]table.Row, len(matches))
+	hlMatches := make([]*table.Row, len(matches))
+	for i, match := range matches {
+		copies[i] = rows[match.Index].Copy()
+		copies[i].IsFiltered = true
+		copies[i].MatchedIndex = match.MatchedIndexes
+		hlMatches[i] = &copies[i]
+	}
+	m.filteredTable.AppendRows(hlMatches...)
+}
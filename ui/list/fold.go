@@ -0,0 +1,37 @@
+package list
+
+// Fold or unfold the section under the cursor
+func (m *Model) toggleFold() {
+	t := m.currentTable()
+	heading := t.HeadingAt(m.cursor)
+	if heading == nil {
+		return
+	}
+
+	t.SetFolded(heading, !heading.Folded)
+	m.cursor = t.IndexOf(heading)
+	m.maxRows = t.LineCount
+	if m.cursorPastViewTop() {
+		m.viewport.SetYOffset(m.cursorLine())
+	}
+}
+
+// Fold or unfold all sections, keeping the cursor in the same section
+func (m *Model) foldAll(folded bool) {
+	t := m.currentTable()
+	row := m.currentRow()
+	heading := t.HeadingAt(m.cursor)
+
+	t.FoldAll(folded)
+	m.maxRows = t.LineCount
+
+	if i := t.IndexOf(row); i >= 0 {
+		m.cursor = i
+	} else if i := t.IndexOf(heading); i >= 0 {
+		m.cursor = i
+	}
+
+	if m.cursorPastViewTop() || m.cursorPastViewBottom() {
+		m.viewport.SetYOffset(m.cursorLine() - m.viewport.Height/2)
+	}
+}
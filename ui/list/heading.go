@@ -0,0 +1,114 @@
+package list
+
+import (
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/kencx/keyb/ui/table"
+)
+
+// Move cursor to the next heading
+func (m *Model) nextHeading() {
+	rows := m.currentTable().VisibleRows()
+	for i := m.cursor + 1; i < len(rows); i++ {
+		if rows[i].IsHeading {
+			if target := m.headingTarget(rows, i); target > m.cursor {
+				m.cursor = target
+				break
+			}
+		}
+	}
+	m.scrollToCursor()
+}
+
+// Move cursor to the heading of the current section, or the previous heading
+// if already there
+func (m *Model) prevHeading() {
+	rows := m.currentTable().VisibleRows()
+	for i := min(m.cursor, len(rows)) - 1; i >= 0; i-- {
+		if rows[i].IsHeading {
+			if target := m.headingTarget(rows, i); target < m.cursor {
+				m.cursor = target
+				break
+			}
+		}
+	}
+	m.scrollToCursor()
+}
+
+// Row to place the cursor on when moving to heading i. This is the first row
+// of the section when headings are skipped
+func (m *Model) headingTarget(rows []*table.Row, i int) int {
+	if m.skipHeadings && i+1 < len(rows) && !rows[i+1].IsHeading {
+		return i + 1
+	}
+	return i
+}
+
+// Move cursor off heading rows, continuing in the direction it moved
+func (m *Model) skipHeadingRow(delta int) {
+	rows := m.currentTable().VisibleRows()
+
+	dir := 1
+	if delta < 0 {
+		dir = -1
+	}
+
+	// try the opposite direction at the first or last section
+	for range 2 {
+		for i := m.cursor; i >= 0 && i < len(rows); i += dir {
+			if !rows[i].IsHeading {
+				if i != m.cursor {
+					m.cursor = i
+					m.scrollToCursor()
+				}
+				return
+			}
+		}
+		dir = -dir
+	}
+}
+
+// Scroll viewport to show the cursor
+func (m *Model) scrollToCursor() {
+	if m.cursorPastViewTop() {
+		m.viewport.SetYOffset(m.cursorLine())
+	} else if m.cursorPastViewBottom() {
+		m.viewport.SetYOffset(m.cursorLine() + m.cursorHeight() - m.viewport.Height)
+	}
+}
+
+func (m *Model) openAppPicker() tea.Cmd {
+	var items []pickerItem
+	for _, h := range m.table.GetCopyOfHeadings() {
+		items = append(items, pickerItem{label: h.Text, value: h.Text})
+	}
+	if len(items) == 0 {
+		return nil
+	}
+
+	return m.picker.open("Jump to app", items, true, func(m *Model, app string) {
+		m.jumpToHeading(app)
+	})
+}
+
+// Clear any filter and move cursor to the heading of app
+func (m *Model) jumpToHeading(app string) {
+	if m.filterState == filtering {
+		m.search = false
+		m.searchBar.Blur()
+		m.searchBar.Reset()
+		m.Reset()
+	}
+
+	for _, r := range m.table.Rows {
+		if r != nil && r.IsHeading && !r.Virtual && r.Text == app {
+			m.table.SetFolded(r, false)
+			m.visibleRows()
+
+			i := m.table.IndexOf(r)
+			m.cursor = m.headingTarget(m.table.VisibleRows(), i)
+			_, line := m.table.Cell(i)
+			m.viewport.SetYOffset(line)
+			return
+		}
+	}
+}
@@ -0,0 +1,71 @@
+package list
+
+import (
+	"github.com/charmbracelet/bubbles/key"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/kencx/keyb/config"
+)
+
+// HelpMsg requests the key bindings overlay to be shown
+type HelpMsg struct{}
+
+func (k KeyMap) ShortHelp() []key.Binding {
+	return []key.Binding{k.Search, k.Up, k.Down, k.Copy, k.Fold, k.Help, k.Quit}
+}
+
+func (k KeyMap) FullHelp() [][]key.Binding {
+	return [][]key.Binding{
+		{
+			k.Up, k.Down, k.HalfUp, k.HalfDown, k.FullUp, k.FullDown,
+			k.GoToFirstLine, k.GoToLastLine, k.GoToTop, k.GoToMiddle, k.GoToBottom,
+			k.CenterCursor, k.Left, k.Right,
+		},
+		{k.NextHeading, k.PrevHeading, k.AppPicker, k.Fold, k.CollapseAll, k.ExpandAll},
+		{
+			k.Search, k.ClearSearch, k.Normal, k.Accept, k.UpFocus, k.DownFocus,
+			k.HistoryPrev, k.HistoryNext, k.HistorySearch, k.SavedQueries,
+		},
+		{k.Pin, k.Copy, k.Delete, k.Undo, k.Redo, k.Help, k.Quit},
+		{
+			k.CharacterForward, k.CharacterBackward, k.WordForward, k.WordBackward,
+			k.DeleteWordBackward, k.DeleteWordForward, k.DeleteAfterCursor,
+			k.DeleteBeforeCursor, k.DeleteCharacterBackward, k.DeleteCharacterForward,
+			k.LineStart, k.LineEnd, k.Paste,
+		},
+	}
+}
+
+// names of FullHelp groups
+var helpGroups = []string{"Navigation", "Headings", "Search", "Actions", "Search bar"}
+
+// Key bindings as keyb apps, so they can be shown like any other keyb file
+func (k KeyMap) HelpApps() config.Apps {
+	var apps config.Apps
+	for i, group := range k.FullHelp() {
+		app := &config.App{Name: "keyb " + helpGroups[i]}
+		for _, b := range group {
+			if b.Help().Key == "" {
+				continue
+			}
+			app.Keybinds = append(app.Keybinds, config.KeyBind{
+				Name: b.Help().Desc,
+				Key:  b.Help().Key,
+			})
+		}
+		apps = append(apps, app)
+	}
+	return apps
+}
+
+func (m *Model) Keys() KeyMap {
+	return m.keys
+}
+
+// Whether key presses are taken by the search bar or a menu
+func (m *Model) InputActive() bool {
+	return m.searchMode() || m.picker.active
+}
+
+func showHelp() tea.Msg {
+	return HelpMsg{}
+}
@@ -0,0 +1,120 @@
+package list
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/kencx/keyb/config"
+)
+
+func (m *Model) SetHistory(h *config.History) {
+	m.history = h
+	m.historyIndex = -1
+}
+
+// Recall previous query from history
+func (m *Model) historyPrev() {
+	if m.history == nil || m.history.Len() == 0 {
+		return
+	}
+
+	if m.historyIndex < 0 {
+		m.historyDraft = m.searchBar.Value()
+		m.historyIndex = m.history.Len()
+	}
+	if m.historyIndex > 0 {
+		m.historyIndex--
+	}
+	m.setQuery(m.history.Entries()[m.historyIndex])
+}
+
+// Recall next query from history, returning to the draft past the last entry
+func (m *Model) historyNext() {
+	if m.history == nil || m.historyIndex < 0 {
+		return
+	}
+
+	m.historyIndex++
+	if m.historyIndex >= m.history.Len() {
+		m.historyIndex = -1
+		m.setQuery(m.historyDraft)
+		return
+	}
+	m.setQuery(m.history.Entries()[m.historyIndex])
+}
+
+// Search history backwards for an older query containing the draft
+func (m *Model) historySearch() {
+	if m.history == nil || m.history.Len() == 0 {
+		return
+	}
+
+	if m.historyIndex < 0 {
+		m.historyDraft = m.searchBar.Value()
+		m.historyIndex = m.history.Len()
+	}
+
+	entries := m.history.Entries()
+	for i := m.historyIndex - 1; i >= 0; i-- {
+		if strings.Contains(entries[i], m.historyDraft) {
+			m.historyIndex = i
+			m.setQuery(entries[i])
+			return
+		}
+	}
+}
+
+// Save current query to history
+func (m *Model) recordQuery() {
+	m.historyIndex = -1
+	if m.history == nil {
+		return
+	}
+
+	// history is best effort and must not interrupt the session
+	_ = m.history.Add(m.searchBar.Value())
+}
+
+func (m *Model) setQuery(query string) {
+	m.searchBar.SetValue(query)
+	m.searchBar.CursorEnd()
+}
+
+// Apply query to filter rows without entering search mode
+func (m *Model) ApplyQuery(query string) {
+	m.setQuery(query)
+	m.filterState = filtering
+	m.cursorToBeginning()
+	m.viewport.GotoTop()
+	m.pendingFilter = m.filterRows()
+	m.visibleRows()
+}
+
+func (m *Model) openSavedQueries() {
+	if len(m.saved) == 0 {
+		return
+	}
+
+	var (
+		names []string
+		width int
+	)
+	for name := range m.saved {
+		names = append(names, name)
+		width = max(width, len(name))
+	}
+	sort.Strings(names)
+
+	items := make([]pickerItem, 0, len(names))
+	for _, name := range names {
+		items = append(items, pickerItem{
+			label: fmt.Sprintf("%-*s  %s", width, name, m.saved[name]),
+			value: m.saved[name],
+		})
+	}
+
+	m.picker.open("Saved queries", items, false, func(m *Model, query string) {
+		m.ApplyQuery(query)
+	})
+}
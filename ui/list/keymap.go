@@ -23,12 +23,35 @@ type KeyMap struct {
 	GoToTop       key.Binding
 	GoToMiddle    key.Binding
 	GoToBottom    key.Binding
+	NextHeading   key.Binding
+	PrevHeading   key.Binding
+	AppPicker     key.Binding
+	Left          key.Binding
+	Right         key.Binding
 
 	CenterCursor key.Binding
 
 	Search      key.Binding
 	ClearSearch key.Binding
 	Normal      key.Binding
+	Accept      key.Binding
+
+	HistoryPrev   key.Binding
+	HistoryNext   key.Binding
+	HistorySearch key.Binding
+	SavedQueries  key.Binding
+
+	Pin    key.Binding
+	Copy   key.Binding
+	Delete key.Binding
+	Undo   key.Binding
+	Redo   key.Binding
+
+	Fold        key.Binding
+	CollapseAll key.Binding
+	ExpandAll   key.Binding
+
+	Help key.Binding
 
 	TextInputKeyMap
 }
@@ -54,49 +77,91 @@ type TextInputKeyMap struct {
 
 func CreateKeyMap(keys config.Keys) KeyMap {
 	return KeyMap{
-		Quit:          SetKey(keys.Quit),
-		Up:            SetKey(keys.Up),
-		Down:          SetKey(keys.Down),
-		HalfUp:        SetKey(keys.HalfUp),
-		HalfDown:      SetKey(keys.HalfDown),
-		FullUp:        SetKey(keys.FullUp),
-		FullDown:      SetKey(keys.FullDown),
-		UpFocus:       SetKey(keys.UpFocus),
-		DownFocus:     SetKey(keys.DownFocus),
-		GoToFirstLine: SetKey(keys.GoToFirstLine),
-		GoToLastLine:  SetKey(keys.GoToLastLine),
-		GoToTop:       SetKey(keys.GoToTop),
-		GoToMiddle:    SetKey(keys.GoToMiddle),
-		GoToBottom:    SetKey(keys.GoToBottom),
-
-		Search:      SetKey(keys.Search),
-		ClearSearch: SetKey(keys.ClearSearch),
-		Normal:      SetKey(keys.Normal),
+		Quit:          SetKey(keys.Quit, "quit"),
+		Up:            SetKey(keys.Up, "up"),
+		Down:          SetKey(keys.Down, "down"),
+		HalfUp:        SetKey(keys.HalfUp, "half page up"),
+		HalfDown:      SetKey(keys.HalfDown, "half page down"),
+		FullUp:        SetKey(keys.FullUp, "page up"),
+		FullDown:      SetKey(keys.FullDown, "page down"),
+		UpFocus:       SetKey(keys.UpFocus, "up in search"),
+		DownFocus:     SetKey(keys.DownFocus, "down in search"),
+		GoToFirstLine: SetKey(keys.GoToFirstLine, "first line"),
+		GoToLastLine:  SetKey(keys.GoToLastLine, "last line"),
+		GoToTop:       SetKey(keys.GoToTop, "top of view"),
+		GoToMiddle:    SetKey(keys.GoToMiddle, "middle of view"),
+		GoToBottom:    SetKey(keys.GoToBottom, "bottom of view"),
+		CenterCursor:  SetKey(keys.CenterCursor, "center cursor"),
+		NextHeading:   SetKey(keys.NextHeading, "next heading"),
+		PrevHeading:   SetKey(keys.PrevHeading, "previous heading"),
+		AppPicker:     SetKey(keys.AppPicker, "jump to app"),
+		Left:          SetKey(keys.Left, "column left"),
+		Right:         SetKey(keys.Right, "column right"),
+
+		Search:      SetKey(keys.Search, "search"),
+		ClearSearch: SetKey(keys.ClearSearch, "clear search"),
+		Normal:      SetKey(keys.Normal, "normal mode"),
+		Accept:      SetKey(keys.Accept, "accept search, select row"),
+
+		HistoryPrev:   SetKey(keys.HistoryPrev, "previous query"),
+		HistoryNext:   SetKey(keys.HistoryNext, "next query"),
+		HistorySearch: SetKey(keys.HistorySearch, "search history"),
+		SavedQueries:  SetKey(keys.SavedQueries, "saved queries"),
+
+		Pin:    SetKey(keys.Pin, "pin"),
+		Copy:   SetKey(keys.Copy, "copy key"),
+		Delete: SetKey(keys.Delete, "delete key binding"),
+		Undo:   SetKey(keys.Undo, "undo"),
+		Redo:   SetKey(keys.Redo, "redo"),
+
+		Fold:        SetKey(keys.Fold, "fold heading"),
+		CollapseAll: SetKey(keys.CollapseAll, "collapse all"),
+		ExpandAll:   SetKey(keys.ExpandAll, "expand all"),
+
+		Help: SetKey(keys.Help, "help"),
 
 		TextInputKeyMap: TextInputKeyMap{
-			CharacterForward:        SetKey("right"),
-			CharacterBackward:       SetKey("left"),
-			WordForward:             SetKey(keys.CursorWordForward),
-			WordBackward:            SetKey(keys.CursorWordBackward),
-			DeleteWordBackward:      SetKey(keys.CursorDeleteWordBackward),
-			DeleteWordForward:       SetKey(keys.CursorDeleteWordForward),
-			DeleteAfterCursor:       SetKey(keys.CursorDeleteAfterCursor),
-			DeleteBeforeCursor:      SetKey(keys.CursorDeleteBeforeCursor),
-			DeleteCharacterBackward: SetKey("backspace"),
-			DeleteCharacterForward:  SetKey("delete"),
-			LineStart:               SetKey(keys.CursorLineStart),
-			LineEnd:                 SetKey(keys.CursorLineEnd),
-			Paste:                   SetKey(keys.CursorPaste),
-			AcceptSuggestion:        SetKey("tab"),
-			NextSuggestion:          SetKey("ctrl+n"),
-			PrevSuggestion:          SetKey("ctrl+p"),
+			CharacterForward:        SetKey("right", "character forward"),
+			CharacterBackward:       SetKey("left", "character backward"),
+			WordForward:             SetKey(keys.CursorWordForward, "word forward"),
+			WordBackward:            SetKey(keys.CursorWordBackward, "word backward"),
+			DeleteWordBackward:      SetKey(keys.CursorDeleteWordBackward, "delete word backward"),
+			DeleteWordForward:       SetKey(keys.CursorDeleteWordForward, "delete word forward"),
+			DeleteAfterCursor:       SetKey(keys.CursorDeleteAfterCursor, "delete after cursor"),
+			DeleteBeforeCursor:      SetKey(keys.CursorDeleteBeforeCursor, "delete before cursor"),
+			DeleteCharacterBackward: SetKey("backspace", "delete character backward"),
+			DeleteCharacterForward:  SetKey("delete", "delete character forward"),
+			LineStart:               SetKey(keys.CursorLineStart, "line start"),
+			LineEnd:                 SetKey(keys.CursorLineEnd, "line end"),
+			Paste:                   SetKey(keys.CursorPaste, "paste"),
+			AcceptSuggestion:        SetKey("tab", "accept suggestion"),
+			NextSuggestion:          SetKey("ctrl+n", "next suggestion"),
+			PrevSuggestion:          SetKey("ctrl+p", "previous suggestion"),
 		},
 	}
 }
 
-func SetKey(s string) key.Binding {
+// Bindings available in normal mode
+func (k KeyMap) normalBindings() []key.Binding {
+	return []key.Binding{
+		k.Quit,
+		k.Up, k.Down, k.HalfUp, k.HalfDown, k.FullUp, k.FullDown,
+		k.UpFocus, k.DownFocus,
+		k.GoToFirstLine, k.GoToLastLine, k.GoToTop, k.GoToMiddle, k.GoToBottom,
+		k.NextHeading, k.PrevHeading, k.AppPicker, k.CenterCursor,
+		k.Left, k.Right,
+		k.Search, k.ClearSearch, k.SavedQueries,
+		k.Accept, k.Pin, k.Copy, k.Delete, k.Undo, k.Redo,
+		k.Fold, k.CollapseAll, k.ExpandAll,
+		k.Help,
+	}
+}
+
+func SetKey(s, desc string) key.Binding {
+	keys := splitAndTrim(s, ",")
 	return key.NewBinding(
-		key.WithKeys(splitAndTrim(s, ",")...),
+		key.WithKeys(keys...),
+		key.WithHelp(strings.Join(keys, "/"), desc),
 	)
 }
 
@@ -1,12 +1,15 @@
 package list
 
 import (
+	"context"
+	"time"
+
 	"github.com/kencx/keyb/config"
 	"github.com/kencx/keyb/ui/table"
 
 	"github.com/charmbracelet/bubbles/cursor"
+	"github.com/charmbracelet/bubbles/help"
 	"github.com/charmbracelet/bubbles/textinput"
-	"github.com/charmbracelet/bubbles/viewport"
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/lipgloss"
 )
@@ -20,27 +23,66 @@ const (
 
 type Model struct {
 	keys     KeyMap
-	viewport viewport.Model
+	viewport viewport
 	table    *table.Model
 
 	searchBar         textinput.Model
 	search            bool
 	startInSearchMode bool
 
+	history      *config.History
+	historyIndex int // entry being recalled, -1 when not browsing history
+	historyDraft string
+	saved        map[string]string
+	picker       picker
+	seq          sequence
+
+	usage    *config.Usage
+	frecency bool
+
 	filterState    filterState
 	filteredTable  *table.Model
 	currentHeading string
 
-	title   string
-	debug   bool
-	cursor  int
-	maxRows int // max number of rows regardless of filterState
+	// queries in flight are tagged with a generation and cancelled when a
+	// newer query starts
+	generation    int
+	cancel        context.CancelFunc
+	searching     bool
+	asyncRows     int
+	pendingFilter tea.Cmd
+
+	quitting      bool
+	title         string
+	titleLocation string
+	statusLine    statusLine
+	file          string
+	message       string
+	reload        ReloadState
+	debug         bool
+	cursor        int
+	skipHeadings  bool
+	maxRows       int // max number of rows regardless of filterState
 
 	margin         int
 	padding        int
 	scrollOffset   int
 	border         lipgloss.Style
 	counterStyle   lipgloss.Style
+	titleStyle     lipgloss.Style
+	shortHelp      help.Model
+	showHelp       bool
+	grid           bool
+	wrap           bool
+	printColumns   int
+	printWidth     int
+	scrollbar      bool
+	scrollbarStyle lipgloss.Style
+	headingClick   string
+	mouse          mouse
+	rowStyles      table.RowStyles
+	columns        []table.Column
+	prefixSep      string
 	promptLocation string
 }
 
@@ -49,7 +91,7 @@ func New(t *table.Model, c *config.Config) Model {
 
 	m := Model{
 		keys: keyMap,
-		viewport: viewport.Model{
+		viewport: viewport{
 			YOffset:           0,
 			MouseWheelDelta:   3,
 			MouseWheelEnabled: c.Mouse,
@@ -57,37 +99,70 @@ func New(t *table.Model, c *config.Config) Model {
 		table: t,
 
 		searchBar: textinput.Model{
-			Prompt:           c.Prompt,
-			PromptStyle:      lipgloss.NewStyle().Foreground(lipgloss.Color(c.PromptColor)),
-			Placeholder:      c.Placeholder,
-			PlaceholderStyle: lipgloss.NewStyle().Foreground(lipgloss.Color("240")),
-			EchoCharacter:    '*',
-			CharLimit:        0,
-			Cursor:           cursor.New(),
-			KeyMap:           textinput.KeyMap(keyMap.TextInputKeyMap),
-			ShowSuggestions:  false,
+			Prompt:          c.Prompt,
+			Placeholder:     c.Placeholder,
+			EchoCharacter:   '*',
+			CharLimit:       0,
+			Cursor:          cursor.New(),
+			KeyMap:          textinput.KeyMap(keyMap.TextInputKeyMap),
+			ShowSuggestions: false,
 		},
 		startInSearchMode: c.SearchMode,
+		historyIndex:      -1,
+		saved:             c.Saved,
+		frecency:          c.Frecency,
 
 		filteredTable: table.NewEmpty(t.LineCount),
-
-		title:   c.Title,
-		debug:   c.Debug,
-		cursor:  0,
-		maxRows: t.LineCount,
+		asyncRows:     asyncFilterRows,
+
+		title:         c.Title,
+		titleLocation: c.TitleLocation,
+		statusLine:    statusLine{c.StatusLeft, c.StatusCenter, c.StatusRight},
+		file:          c.KeybFile(),
+		debug:         c.Debug,
+		cursor:        0,
+		maxRows:       t.LineCount,
+		skipHeadings:  c.SkipHeadings,
+		seq:           sequence{timeout: time.Duration(c.KeyTimeout) * time.Millisecond},
 
 		margin:         c.Margin,
 		padding:        c.Padding,
 		scrollOffset:   5,
 		counterStyle:   lipgloss.NewStyle().Faint(true).Margin(0, 1),
 		promptLocation: c.PromptLocation,
+		shortHelp:      help.New(),
+		showHelp:       c.ShowHelp,
+		scrollbar:      c.Scrollbar,
+		headingClick:   c.HeadingClick,
 	}
 
 	m.table.SepWidth = c.SepWidth
 	m.filteredTable.SepWidth = c.SepWidth
+
+	m.grid = c.Layout == "grid"
+	if m.grid {
+		m.printColumns = c.GridColumns
+	}
+	m.wrap = c.Wrap
+	for _, t := range []*table.Model{m.table, m.filteredTable} {
+		t.Wrap = m.wrap
+		t.Grid = m.grid
+		t.Columns = c.GridColumns
+	}
 	m.scrollOffset += (m.margin * 2) + (m.padding * 2)
+	if m.title != "" && m.titleLocation != "border" {
+		m.scrollOffset++
+	}
+	if m.showHelp {
+		m.scrollOffset++
+	}
 	m.style(c)
 
+	if c.Collapsed {
+		m.table.FoldAll(true)
+		m.maxRows = m.table.LineCount
+	}
+
 	if m.startInSearchMode {
 		m.startSearch()
 	}
@@ -96,16 +171,19 @@ func New(t *table.Model, c *config.Config) Model {
 }
 
 func (m *Model) style(c *config.Config) {
-	if c.PlaceholderFg != "" || c.PlaceholderBg != "" {
-		m.searchBar.PlaceholderStyle = lipgloss.NewStyle().
-			Foreground(lipgloss.Color(c.PlaceholderFg)).
-			Background(lipgloss.Color(c.PlaceholderBg))
-	}
+	p := c.Palette()
+
+	m.searchBar.PromptStyle = lipgloss.NewStyle().Foreground(themeColor(p.PromptColor))
+	m.searchBar.PlaceholderStyle = lipgloss.NewStyle().
+		Foreground(themeColor(p.PlaceholderFg)).
+		Background(themeColor(p.PlaceholderBg))
 
-	if c.CounterFg != "" || c.CounterBg != "" {
-		m.counterStyle = lipgloss.NewStyle().Foreground(lipgloss.Color(c.CounterFg)).Background(lipgloss.Color(c.CounterBg)).Margin(0, 1)
+	if !p.CounterFg.Empty() || !p.CounterBg.Empty() {
+		m.counterStyle = lipgloss.NewStyle().Foreground(themeColor(p.CounterFg)).Background(themeColor(p.CounterBg)).Margin(0, 1)
 	}
 
+	m.titleStyle = lipgloss.NewStyle().Foreground(themeColor(p.TitleFg)).Background(themeColor(p.TitleBg))
+
 	var b lipgloss.Border
 	switch c.BorderStyle {
 	case "normal":
@@ -119,35 +197,106 @@ func (m *Model) style(c *config.Config) {
 	default:
 		b = lipgloss.HiddenBorder()
 	}
-	m.border = lipgloss.NewStyle().BorderStyle(b).BorderForeground(lipgloss.Color(c.BorderColor))
+	m.border = lipgloss.NewStyle().BorderStyle(b).BorderForeground(themeColor(p.BorderColor))
+	m.scrollbarStyle = lipgloss.NewStyle().Foreground(themeColor(p.BorderColor))
+
+	cursor := lipgloss.NewStyle().Bold(true).
+		Foreground(themeColor(p.CursorFg)).
+		Background(themeColor(p.CursorBg))
+
+	heading := lipgloss.NewStyle().Margin(0, 1).Bold(true).
+		Foreground(themeColor(p.HeadingFg)).
+		Background(themeColor(p.HeadingBg))
+
+	m.picker.heading = heading
+	m.picker.normal = lipgloss.NewStyle().Margin(0, 2)
+	m.picker.selected = cursor.Margin(0, 2)
 
 	// row specific config
 	if !m.table.Empty() {
-		cursor := lipgloss.NewStyle().Bold(true).
-			Foreground(lipgloss.Color(c.CursorFg)).
-			Background(lipgloss.Color(c.CursorBg))
-
-		s := table.RowStyles{
-			Normal:          lipgloss.NewStyle().Margin(0, 2).TabWidth(lipgloss.NoTabConversion),
-			Heading:         lipgloss.NewStyle().Margin(0, 1).Bold(true).TabWidth(lipgloss.NoTabConversion),
+		m.rowStyles = table.RowStyles{
+			Normal: lipgloss.NewStyle().Margin(0, 2).
+				Foreground(themeColor(p.RowFg)).
+				Background(themeColor(p.RowBg)).
+				TabWidth(lipgloss.NoTabConversion),
+			Heading:         heading.TabWidth(lipgloss.NoTabConversion),
 			Selected:        cursor.Margin(0, 2).TabWidth(lipgloss.NoTabConversion),
 			SelectedHeading: cursor.Margin(0, 1).Bold(true).TabWidth(lipgloss.NoTabConversion),
 			Filtered: lipgloss.NewStyle().
-				Foreground(lipgloss.Color(c.FilterFg)).
-				Background(lipgloss.Color(c.FilterBg)).
+				Foreground(themeColor(p.FilterFg)).
+				Background(themeColor(p.FilterBg)).
 				TabWidth(lipgloss.NoTabConversion),
+			Key: lipgloss.NewStyle().
+				Foreground(themeColor(p.KeyFg)).
+				Background(themeColor(p.KeyBg)),
+			Prefix: lipgloss.NewStyle().
+				Foreground(themeColor(p.PrefixFg)).
+				Background(themeColor(p.PrefixBg)),
+		}
+
+		m.columns = rowColumns(c.RowColumns())
+		m.prefixSep = c.PrefixSep
+		m.styleRows(m.table)
+	}
+}
+
+func (m *Model) styleRows(t *table.Model) {
+	for _, row := range t.Rows {
+		if row == nil {
+			continue
+		}
+		row.PrefixSep = m.prefixSep
+		row.Columns = m.columns
+		row.Styles = m.rowStyles
+	}
+	t.Invalidate()
+}
+
+// Convert configured columns to table columns
+func rowColumns(columns []config.Column) []table.Column {
+	res := make([]table.Column, len(columns))
+	for i, c := range columns {
+		style := lipgloss.NewStyle()
+		if c.Bold {
+			style = style.Bold(true)
+		}
+		if c.Italic {
+			style = style.Italic(true)
+		}
+		if c.Fg != "" {
+			style = style.Foreground(lipgloss.Color(c.Fg))
+		}
+		if c.Bg != "" {
+			style = style.Background(lipgloss.Color(c.Bg))
 		}
 
-		for _, row := range m.table.Rows {
-			row.PrefixSep = c.PrefixSep
-			row.Reversed = c.Reverse
-			row.Styles = s
+		align := table.AlignLeft
+		switch c.Align {
+		case "right":
+			align = table.AlignRight
+		case "center":
+			align = table.AlignCenter
 		}
+
+		res[i] = table.Column{Field: c.Field, Align: align, MaxWidth: c.MaxWidth, Style: style}
 	}
+	return res
 }
 
+// Convert theme color to a terminal color, adapting to the terminal
+// background if light and dark colors differ
+func themeColor(c config.ThemeColor) lipgloss.TerminalColor {
+	if c.Light == c.Dark {
+		return lipgloss.Color(c.Dark)
+	}
+	return lipgloss.AdaptiveColor{Light: c.Light, Dark: c.Dark}
+}
+
+// Filter with the query applied before the program started
 func (m *Model) Init() tea.Cmd {
-	return nil
+	cmd := m.pendingFilter
+	m.pendingFilter = nil
+	return cmd
 }
 
 func (m *Model) Resize(width, height int) {
@@ -157,6 +306,7 @@ func (m *Model) Resize(width, height int) {
 
 // Resets list to unfiltered state
 func (m *Model) Reset() {
+	m.cancelFilter()
 	m.filteredTable.Reset()
 	m.filterState = unfiltered
 	m.currentHeading = ""
@@ -166,11 +316,15 @@ func (m *Model) Reset() {
 
 // Sets items to be shown. All items are shown unless filtered
 func (m *Model) visibleRows() {
+	m.SyncContent(m.currentTable())
+}
+
+// Table of rows being shown
+func (m *Model) currentTable() *table.Model {
 	if !m.filteredTable.Empty() {
-		m.SyncContent(m.filteredTable)
-	} else {
-		m.SyncContent(m.table)
+		return m.filteredTable
 	}
+	return m.table
 }
 
 // Sync content by updating cursor and visible rows
@@ -184,22 +338,40 @@ func (m *Model) SyncContent(table *table.Model) {
 		m.cursor = table.LineCount - 1
 	}
 
-	for i, row := range table.Rows {
-		if i == m.cursor {
-			row.IsSelected = true
-			m.currentHeading = row.Heading
-		} else {
-			row.IsSelected = false
-		}
+	if row := table.Select(m.cursor); row != nil {
+		m.currentHeading = row.Heading
+	}
+	if table.Grid {
+		// grid columns are balanced over all rows, so the grid is rendered whole
+		m.viewport.SetContent(table.Render())
+	} else {
+		m.viewport.SetLines(table.TotalLines(), table.RenderLines)
 	}
-	m.viewport.SetContent(table.Render())
 	m.maxRows = table.LineCount
 }
 
 func (m *Model) UnstyledString() string {
+	if m.printColumns > 0 || m.printWidth > 0 {
+		return m.table.GetAlignedGrid(m.printColumns, m.printWidth)
+	}
 	return m.table.GetAlignedRows()
 }
 
+// Flow unstyled rows into columns, or as many columns as fit width
+func (m *Model) SetPrintLayout(columns, width int) {
+	m.printColumns = columns
+	m.printWidth = width
+}
+
+func (m *Model) quit() tea.Cmd {
+	m.quitting = true
+	return tea.Quit
+}
+
+func (m *Model) Quitting() bool {
+	return m.quitting
+}
+
 func (m *Model) searchMode() bool {
 	return m.search && m.searchBar.Focused()
 }
@@ -211,6 +383,24 @@ func (m *Model) startSearch() tea.Cmd {
 	return textinput.Blink
 }
 
+// Move cursor up n rows. Single steps loop around at the beginning
+func (m *Model) cursorUp(n int) {
+	m.cursor -= n
+	if n > 1 && m.cursorPastBeginning() {
+		m.cursorToBeginning()
+	}
+	m.scrollToCursor()
+}
+
+// Move cursor down n rows. Single steps loop around at the end
+func (m *Model) cursorDown(n int) {
+	m.cursor += n
+	if n > 1 && m.cursorPastEnd() {
+		m.cursorToEnd()
+	}
+	m.scrollToCursor()
+}
+
 func (m *Model) cursorToBeginning() {
 	m.cursor = 0
 }
@@ -220,23 +410,51 @@ func (m *Model) cursorToEnd() {
 }
 
 func (m *Model) cursorToViewTop() {
-	m.cursor = m.viewport.YOffset + 3
+	m.cursor = m.rowAtLine(m.viewport.YOffset + 3)
 }
 
 func (m *Model) cursorToViewMiddle() {
-	m.cursor = (m.viewport.YOffset + m.viewport.Height) / 2
+	m.cursor = m.rowAtLine((m.viewport.YOffset + m.viewport.Height) / 2)
 }
 
 func (m *Model) cursorToViewBottom() {
-	m.cursor = m.viewport.YOffset + m.viewport.Height - 3
+	m.cursor = m.rowAtLine(m.viewport.YOffset + m.viewport.Height - 3)
+}
+
+// Move cursor n columns left or right in grid layout, keeping its line
+func (m *Model) cursorToColumn(n int) {
+	if !m.grid {
+		return
+	}
+
+	col, line := m.currentTable().Cell(m.cursor)
+	m.cursor = m.currentTable().RowAt(col+n, line)
+	m.scrollToCursor()
+}
+
+// Line of the cursor in the rendered rows
+func (m *Model) cursorLine() int {
+	_, line := m.currentTable().Cell(m.cursor)
+	return line
+}
+
+// Number of rendered lines of the cursor's row
+func (m *Model) cursorHeight() int {
+	return m.currentTable().Lines(m.cursor)
+}
+
+// Row at line in the cursor's column
+func (m *Model) rowAtLine(line int) int {
+	col, _ := m.currentTable().Cell(m.cursor)
+	return m.currentTable().RowAt(col, line)
 }
 
 func (m *Model) cursorPastViewTop() bool {
-	return m.cursor < m.viewport.YOffset
+	return m.cursorLine() < m.viewport.YOffset
 }
 
 func (m *Model) cursorPastViewBottom() bool {
-	return m.cursor > m.viewport.YOffset+m.viewport.Height-1
+	return m.cursorLine()+m.cursorHeight()-1 > m.viewport.YOffset+m.viewport.Height-1
 }
 
 func (m *Model) cursorPastBeginning() bool {
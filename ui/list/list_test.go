@@ -1,8 +1,13 @@
 package list
 
 import (
+	"path/filepath"
+	"slices"
 	"testing"
 
+	"github.com/charmbracelet/bubbles/cursor"
+	tea "github.com/charmbracelet/bubbletea"
+
 	"github.com/kencx/keyb/config"
 	"github.com/kencx/keyb/ui/table"
 )
@@ -38,7 +43,7 @@ func TestNew(t *testing.T) {
 
 		assertEqual(t, tm.title, "foo")
 		assertEqual(t, tm.debug, true)
-		assertEqual(t, tm.table.Rows[0].Reversed, true)
+		assertEqual(t, tm.table.Rows[0].Columns[0].Field, table.FieldKey)
 		assertEqual(t, tm.viewport.MouseWheelEnabled, true)
 		assertEqual(t, tm.searchBar.Prompt, "prompt")
 		assertEqual(t, tm.searchBar.Placeholder, "placeholder")
@@ -78,3 +83,639 @@ func assertEqual[T comparable](t *testing.T, got, want T) {
 		t.Errorf("got %#v, want %#v", got, want)
 	}
 }
+
+func TestHistoryRecall(t *testing.T) {
+	h, err := config.NewHistory(filepath.Join(t.TempDir(), "history"), 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, q := range []string{"foo", "bar", "baz"} {
+		if err := h.Add(q); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	tm := New(testTable, testConfig)
+	tm.SetHistory(h)
+	tm.setQuery("ba")
+
+	tm.historyPrev()
+	assertEqual(t, tm.searchBar.Value(), "baz")
+	tm.historyPrev()
+	assertEqual(t, tm.searchBar.Value(), "bar")
+	tm.historyNext()
+	tm.historyNext()
+	assertEqual(t, tm.searchBar.Value(), "ba")
+	assertEqual(t, tm.historyIndex, -1)
+
+	tm.setQuery("fo")
+	tm.historySearch()
+	assertEqual(t, tm.searchBar.Value(), "foo")
+}
+
+func TestApplyQuery(t *testing.T) {
+	tm := New(testTable, testConfig)
+	tm.ApplyQuery("ba")
+
+	assertEqual(t, tm.filterState, filtering)
+	assertEqual(t, tm.filteredTable.LineCount, 2)
+	assertEqual(t, tm.searchMode(), false)
+}
+
+func TestTogglePin(t *testing.T) {
+	u, err := config.NewUsage(filepath.Join(t.TempDir(), "usage.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	rows := []*table.Row{
+		table.NewHeading("fooTable"),
+		table.NewRow("foo", "f", "", "fooTable"),
+		table.NewRow("bar", "b", "", "fooTable"),
+	}
+	tm := New(table.New(rows), testConfig)
+	tm.SetUsage(u)
+
+	tm.cursor = 2
+	tm.togglePin()
+	assertEqual(t, tm.table.LineCount, 5)
+	assertEqual(t, tm.table.Rows[0].Text, favouritesHeading)
+	assertEqual(t, tm.table.Rows[1].Text, "bar")
+	assertEqual(t, tm.table.Rows[1].Virtual, true)
+	assertEqual(t, tm.cursor, 4)
+
+	tm.togglePin()
+	assertEqual(t, tm.table.LineCount, 3)
+	assertEqual(t, tm.cursor, 2)
+	assertEqual(t, len(tm.table.GetPlainRowsWithoutHeadings()), 2)
+}
+
+func TestSelectRow(t *testing.T) {
+	u, err := config.NewUsage(filepath.Join(t.TempDir(), "usage.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	rows := []*table.Row{
+		table.NewHeading("fooTable"),
+		table.NewRow("foo", "f", "", "fooTable"),
+		table.NewRow("bar", "b", "", "fooTable"),
+	}
+	c := testConfig.Copy()
+	c.Keys = config.Keys{Accept: "enter"}
+	tm := New(table.New(rows), c)
+	tm.SetUsage(u)
+
+	tm.cursor = 2
+	tm.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	assertEqual(t, u.Score("fooTable", "bar") > 0, true)
+	assertEqual(t, u.Score("fooTable", "foo"), 0)
+
+	// headings are not used
+	tm.cursor = 0
+	tm.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	assertEqual(t, u.Score("fooTable", "fooTable"), 0)
+}
+
+func TestFrecency(t *testing.T) {
+	u, err := config.NewUsage("")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	rows := []*table.Row{
+		table.NewHeading("git"),
+		table.NewRow("save the archive tag", "", "", "git"),
+		table.NewRow("stat", "", "", "git"),
+		table.NewRow("stat log", "", "", "git"),
+		table.NewRow("stat tag", "", "", "git"),
+	}
+	for range 5 {
+		u.Record("git", "save the archive tag")
+	}
+	u.Record("git", "stat tag")
+
+	c := testConfig.Copy()
+	c.Frecency = true
+	tm := New(table.New(rows), c)
+	tm.SetUsage(u)
+
+	// usage only ranks rows that match equally well
+	tm.ApplyQuery("stat")
+	var got []string
+	for _, r := range tm.filteredTable.Rows {
+		got = append(got, r.Text)
+	}
+	want := []string{"stat", "stat tag", "stat log", "save the archive tag"}
+	if !slices.Equal(got, want) {
+		t.Errorf("got %v, want %v", got, want)
+	}
+}
+
+func TestFold(t *testing.T) {
+	rows := []*table.Row{
+		table.NewHeading("foo"),
+		table.NewRow("a", "1", "", "foo"),
+		table.NewRow("b", "2", "", "foo"),
+		table.NewHeading("bar"),
+		table.NewRow("c", "3", "", "bar"),
+	}
+	tm := New(table.New(rows), testConfig)
+
+	tm.cursor = 2
+	tm.toggleFold()
+	tm.visibleRows()
+	assertEqual(t, tm.cursor, 0)
+	assertEqual(t, tm.maxRows, 3)
+
+	tm.cursor = 2
+	tm.foldAll(true)
+	assertEqual(t, tm.cursor, 1)
+	assertEqual(t, tm.currentRow().Text, "bar")
+
+	tm.foldAll(false)
+	assertEqual(t, tm.cursor, 3)
+	assertEqual(t, tm.maxRows, 5)
+}
+
+func TestHeadingNavigation(t *testing.T) {
+	newModel := func(skip bool) Model {
+		rows := []*table.Row{
+			table.NewHeading("foo"),
+			table.NewRow("a", "1", "", "foo"),
+			table.NewRow("b", "2", "", "foo"),
+			table.NewHeading("bar"),
+			table.NewRow("c", "3", "", "bar"),
+		}
+		tm := New(table.New(rows), testConfig)
+		tm.skipHeadings = skip
+		return tm
+	}
+
+	t.Run("headings", func(t *testing.T) {
+		tm := newModel(false)
+		tm.nextHeading()
+		assertEqual(t, tm.cursor, 3)
+		tm.nextHeading()
+		assertEqual(t, tm.cursor, 3)
+
+		tm.cursor = 4
+		tm.prevHeading()
+		assertEqual(t, tm.cursor, 3)
+		tm.prevHeading()
+		assertEqual(t, tm.cursor, 0)
+	})
+
+	t.Run("typed motions", func(t *testing.T) {
+		tm := newModel(false)
+		tm.keys = CreateKeyMap(config.Keys{NextHeading: "]]", PrevHeading: "[["})
+		press := func(k string) {
+			tm, _ = tm.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune(k)})
+		}
+
+		press("]")
+		assertEqual(t, tm.cursor, 0)
+		press("]")
+		assertEqual(t, tm.cursor, 3)
+
+		// other keys cancel the motion
+		press("[")
+		press("j")
+		press("[")
+		assertEqual(t, tm.cursor, 3)
+		press("[")
+		assertEqual(t, tm.cursor, 0)
+	})
+
+	t.Run("skip headings", func(t *testing.T) {
+		tm := newModel(true)
+		tm.cursor = 1
+		tm.nextHeading()
+		assertEqual(t, tm.cursor, 4)
+		tm.prevHeading()
+		assertEqual(t, tm.cursor, 1)
+
+		tm.cursor = 3
+		tm.skipHeadingRow(-1)
+		assertEqual(t, tm.cursor, 2)
+		tm.cursor = 0
+		tm.skipHeadingRow(-1)
+		assertEqual(t, tm.cursor, 1)
+	})
+
+	t.Run("jump to app", func(t *testing.T) {
+		tm := newModel(false)
+		tm.ApplyQuery("c")
+		tm.jumpToHeading("bar")
+
+		assertEqual(t, tm.filterState, unfiltered)
+		assertEqual(t, tm.cursor, 3)
+	})
+
+	t.Run("app picker", func(t *testing.T) {
+		tm := newModel(false)
+		tm.keys = CreateKeyMap(config.Keys{Up: "k", Down: "j", Accept: "enter"})
+		tm.openAppPicker()
+
+		tm, _ = tm.Update(tea.KeyMsg{Type: tea.KeyDown})
+		assertEqual(t, tm.picker.cursor, 1)
+		tm, _ = tm.Update(tea.KeyMsg{Type: tea.KeyUp})
+		assertEqual(t, tm.picker.cursor, 0)
+
+		// letters are typed into the filter
+		tm, _ = tm.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("j")})
+		assertEqual(t, tm.picker.filter.Value(), "j")
+		assertEqual(t, tm.picker.cursor, 0)
+
+		tm, _ = tm.Update(tea.KeyMsg{Type: tea.KeyBackspace})
+		tm, _ = tm.Update(tea.KeyMsg{Type: tea.KeyDown})
+		tm, _ = tm.Update(tea.KeyMsg{Type: tea.KeyEnter})
+		assertEqual(t, tm.picker.active, false)
+		assertEqual(t, tm.cursor, 3)
+	})
+}
+
+func TestParseSequence(t *testing.T) {
+	tests := []struct {
+		in   string
+		want []string
+	}{
+		{"g", []string{"g"}},
+		{"gg", []string{"g", "g"}},
+		{"]]", []string{"]", "]"}},
+		{"ctrl+d", []string{"ctrl+d"}},
+		{"enter", []string{"enter"}},
+		{"f12", []string{"f12"}},
+		{"ctrl+w j", []string{"ctrl+w", "j"}},
+	}
+
+	for _, tt := range tests {
+		got := parseSequence(tt.in)
+		if !slices.Equal(got, tt.want) {
+			t.Errorf("%q: got %v, want %v", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestSequence(t *testing.T) {
+	keyPress := func(m Model, keys ...string) (Model, tea.Cmd) {
+		var cmd tea.Cmd
+		for _, k := range keys {
+			m, cmd = m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune(k)})
+		}
+		return m, cmd
+	}
+
+	rows := []*table.Row{table.NewHeading("foo")}
+	for range 20 {
+		rows = append(rows, table.NewRow("a", "1", "", "foo"))
+	}
+
+	c := testConfig.Copy()
+	c.Keys = config.Keys{
+		Down:          "j",
+		GoToFirstLine: "gg",
+		GoToLastLine:  "G",
+		CenterCursor:  "zz",
+		Pin:           "z",
+	}
+
+	t.Run("count", func(t *testing.T) {
+		tm, _ := keyPress(New(table.New(rows), c), "1", "0", "j")
+		assertEqual(t, tm.cursor, 10)
+		assertEqual(t, tm.seq.count, 0)
+
+		tm, _ = keyPress(tm, "5", "0", "j")
+		assertEqual(t, tm.cursor, 20)
+
+		tm, _ = keyPress(tm, "3", "G")
+		assertEqual(t, tm.cursor, 2)
+	})
+
+	t.Run("sequence", func(t *testing.T) {
+		tm, _ := keyPress(New(table.New(rows), c), "5", "j", "g")
+		assertEqual(t, tm.cursor, 5)
+		assertEqual(t, tm.seq.String(), "g")
+
+		tm, _ = keyPress(tm, "g")
+		assertEqual(t, tm.cursor, 0)
+		assertEqual(t, tm.seq.String(), "")
+
+		// unknown continuation retries the key on its own
+		tm, _ = keyPress(tm, "g", "j")
+		assertEqual(t, tm.cursor, 1)
+	})
+
+	t.Run("timeout", func(t *testing.T) {
+		tm, cmd := keyPress(New(table.New(rows), c), "z")
+		if cmd == nil {
+			t.Fatal("want timeout cmd")
+		}
+		assertEqual(t, tm.seq.exact, "z")
+
+		tm, _ = tm.Update(sequenceTimeoutMsg{id: tm.seq.id - 1})
+		assertEqual(t, tm.seq.String(), "z")
+
+		tm, _ = tm.Update(sequenceTimeoutMsg{id: tm.seq.id})
+		assertEqual(t, tm.seq.String(), "")
+		assertEqual(t, tm.message, "")
+	})
+}
+
+func TestStatusLine(t *testing.T) {
+	t.Run("expand", func(t *testing.T) {
+		tm := New(table.New([]*table.Row{
+			table.NewHeading("fooTable"),
+			{Text: "foo", Heading: "fooTable"},
+			{Text: "bar", Heading: "fooTable"},
+		}), testConfig)
+		tm.currentHeading = "fooTable"
+		tm.message = "copied"
+
+		got := tm.expandStatus("{filtered}/{total} {heading} {mode} {matcher} {status}")
+		assertEqual(t, got, "3/3 fooTable normal fuzzy copied")
+
+		tm.ApplyQuery("h:foo")
+		got = tm.expandStatus("{filtered}/{total} {mode} {matcher}")
+		assertEqual(t, got, "3/3 filter heading")
+	})
+
+	t.Run("reload", func(t *testing.T) {
+		tm := New(testTable, testConfig)
+		assertEqual(t, tm.expandStatus("[{reload}]"), "[]")
+
+		tm.SetReload(ReloadPending)
+		assertEqual(t, tm.expandStatus("{reload}"), "reload pending")
+		assertEqual(t, tm.status(), "reload pending")
+
+		tm.SetReload(ReloadFailed)
+		tm.message = "keyb file changed"
+		assertEqual(t, tm.expandStatus("{status}"), "reload failed  keyb file changed")
+	})
+
+	alignTests := []struct {
+		name                string
+		left, center, right string
+		width               int
+		want                string
+	}{
+		{"left only", "1/2", "", "", 10, "1/2"},
+		{"left right", "1/2", "", "ok", 10, "1/2     ok"},
+		{"all", "1", "c", "r", 9, "1   c   r"},
+		{"overflow", "1/2", "center", "right", 10, "1/2 center"},
+		{"no width", "1/2", "", "right", 0, "1/2 right"},
+	}
+
+	for _, tt := range alignTests {
+		t.Run(tt.name, func(t *testing.T) {
+			assertEqual(t, alignStatus(tt.left, tt.center, tt.right, tt.width), tt.want)
+		})
+	}
+}
+
+func TestHelp(t *testing.T) {
+	t.Run("apps from key map", func(t *testing.T) {
+		keys := config.DefaultConfig.Keys
+		keys.Copy = "c, ctrl+y"
+
+		apps := CreateKeyMap(keys).HelpApps()
+		assertEqual(t, len(apps), len(helpGroups))
+
+		var found bool
+		for _, app := range apps {
+			for _, kb := range app.Keybinds {
+				if kb.Name == "copy key" {
+					found = true
+					assertEqual(t, kb.Key, "c/ctrl+y")
+				}
+			}
+		}
+		assertEqual(t, found, true)
+	})
+
+	t.Run("help key", func(t *testing.T) {
+		c := config.DefaultConfig.Copy()
+		tm := New(table.New([]*table.Row{table.NewHeading("foo")}), c)
+
+		_, cmd := tm.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("?")})
+		if cmd == nil {
+			t.Fatalf("expected help cmd")
+		}
+		if _, ok := cmd().(HelpMsg); !ok {
+			t.Errorf("expected HelpMsg")
+		}
+	})
+}
+
+func TestMouse(t *testing.T) {
+	newModel := func() Model {
+		rows := []*table.Row{table.NewHeading("foo")}
+		for range 20 {
+			rows = append(rows, &table.Row{Text: "bar", Heading: "foo"})
+		}
+
+		c := config.DefaultConfig.Copy()
+		tm := New(table.New(rows), c)
+		tm, _ = tm.Update(tea.WindowSizeMsg{Width: 40, Height: 15})
+		return tm
+	}
+	press := func(x, y int) tea.MouseMsg {
+		return tea.MouseMsg{X: x, Y: y, Button: tea.MouseButtonLeft, Action: tea.MouseActionPress}
+	}
+
+	t.Run("click row", func(t *testing.T) {
+		tm := newModel()
+		tm, _ = tm.Update(press(5, tm.bodyTop()+3))
+		assertEqual(t, tm.cursor, 3)
+	})
+
+	t.Run("click heading", func(t *testing.T) {
+		tm := newModel()
+		tm, _ = tm.Update(press(5, tm.bodyTop()))
+		assertEqual(t, tm.table.Rows[0].Folded, true)
+		assertEqual(t, tm.maxRows, 1)
+	})
+
+	t.Run("click heading to filter", func(t *testing.T) {
+		tm := newModel()
+		tm.headingClick = "filter"
+		tm, _ = tm.Update(press(5, tm.bodyTop()))
+		assertEqual(t, tm.filterState, filtering)
+		assertEqual(t, tm.searchBar.Value(), "h:foo")
+	})
+
+	t.Run("drag scrollbar", func(t *testing.T) {
+		tm := newModel()
+		x, bottom := tm.scrollbarX(), tm.bodyTop()+tm.viewport.Height-1
+
+		tm, _ = tm.Update(press(x, tm.bodyTop()))
+		tm, _ = tm.Update(tea.MouseMsg{X: x, Y: bottom, Button: tea.MouseButtonLeft, Action: tea.MouseActionMotion})
+		assertEqual(t, tm.viewport.YOffset, tm.viewport.TotalLineCount()-tm.viewport.Height)
+		assertEqual(t, tm.cursorPastViewTop(), false)
+
+		tm, _ = tm.Update(tea.MouseMsg{X: x, Y: bottom, Button: tea.MouseButtonLeft, Action: tea.MouseActionRelease})
+		assertEqual(t, tm.mouse.dragging, false)
+	})
+}
+
+func TestQuit(t *testing.T) {
+	tm := New(testTable, testConfig)
+	tm.keys = CreateKeyMap(config.DefaultConfig.Keys)
+
+	tm, _ = tm.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("q")})
+	assertEqual(t, tm.Quitting(), true)
+}
+
+func TestGridLayout(t *testing.T) {
+	var rows []*table.Row
+	for _, h := range []string{"a", "b", "c"} {
+		rows = append(rows, table.NewHeading(h))
+		rows = append(rows, &table.Row{Text: h + "1", Heading: h}, &table.Row{Text: h + "2", Heading: h})
+	}
+
+	c := config.DefaultConfig.Copy()
+	c.Layout = "grid"
+	c.GridColumns = 3
+	tm := New(table.New(rows), c)
+	tm, _ = tm.Update(tea.WindowSizeMsg{Width: 80, Height: 20})
+
+	keys := []struct {
+		key  string
+		want int
+	}{
+		{"j", 1},
+		{"l", 4},
+		{"l", 7},
+		{"l", 7},
+		{"j", 8},
+		{"h", 5},
+		{"2h", 2},
+	}
+	for _, k := range keys {
+		for _, r := range k.key {
+			tm, _ = tm.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{r}})
+		}
+		assertEqual(t, tm.cursor, k.want)
+	}
+}
+
+func TestWrap(t *testing.T) {
+	rows := []*table.Row{table.NewHeading("tar")}
+	for range 6 {
+		rows = append(rows, &table.Row{
+			Text:    "extract a gzipped archive into a directory",
+			Key:     "tar -xzvf archive.tar.gz -C dir",
+			Heading: "tar",
+		})
+	}
+
+	c := config.DefaultConfig.Copy()
+	c.Wrap = true
+	tm := New(table.New(rows), c)
+	tm, _ = tm.Update(tea.WindowSizeMsg{Width: 50, Height: 20})
+
+	tm, _ = tm.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'G'}})
+	assertEqual(t, tm.cursor, 6)
+	if tm.cursorHeight() < 2 {
+		t.Fatalf("expected wrapped row, got %d lines", tm.cursorHeight())
+	}
+
+	// all lines of the cursor row are in view
+	if tm.cursorLine() < tm.viewport.YOffset ||
+		tm.cursorLine()+tm.cursorHeight() > tm.viewport.YOffset+tm.viewport.Height {
+		t.Errorf("cursor lines %d-%d out of view at %d", tm.cursorLine(),
+			tm.cursorLine()+tm.cursorHeight(), tm.viewport.YOffset)
+	}
+
+	// moving down scrolls until the last line of the row is shown
+	tm, _ = tm.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'g'}})
+	for range 4 {
+		tm, _ = tm.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'j'}})
+	}
+	assertEqual(t, tm.cursor, 4)
+	assertEqual(t, tm.viewport.YOffset, tm.cursorLine()+tm.cursorHeight()-tm.viewport.Height)
+
+	// lines of the wrapped row belong to it
+	assertEqual(t, tm.rowAtLine(tm.cursorLine()+1), 4)
+}
+
+func TestAsyncFilter(t *testing.T) {
+	tm := New(testTable, testConfig)
+	tm.asyncRows = 0
+
+	tm.startSearch()
+	tm.searchBar.SetValue("ba")
+	stale := tm.filterRows()
+	assertEqual(t, tm.searching, true)
+	assertEqual(t, tm.status(), "searching…")
+
+	tm.searchBar.SetValue("foo")
+	cmd := tm.filterRows()
+
+	// cancelled queries return no results
+	assertEqual(t, stale(), tea.Msg(nil))
+
+	// cursor blinks do not restart the search
+	generation := tm.generation
+	tm, _ = tm.Update(cursor.BlinkMsg{})
+	assertEqual(t, tm.generation, generation)
+	assertEqual(t, tm.searching, true)
+
+	tm, _ = tm.Update(cmd())
+	assertEqual(t, tm.searching, false)
+	assertEqual(t, tm.filteredTable.LineCount, 1)
+	assertEqual(t, tm.filteredTable.Rows[0].Text, "foo")
+
+	// results of older queries are dropped
+	tm, _ = tm.Update(filterMsg{generation: tm.generation - 1})
+	assertEqual(t, tm.filteredTable.Rows[0].Text, "foo")
+}
+
+func TestEdit(t *testing.T) {
+	newTable := func(names ...string) *table.Model {
+		rows := []*table.Row{table.NewHeading("foo")}
+		for _, name := range names {
+			rows = append(rows, table.NewRow(name, "1", "", "foo"))
+		}
+		rows = append(rows, table.NewHeading("bar"), table.NewRow("x", "2", "", "bar"))
+		return table.New(rows)
+	}
+
+	c := testConfig.Copy()
+	c.Keys = config.Keys{
+		Down:   "j",
+		Fold:   "tab",
+		Delete: "dd",
+		Undo:   "u",
+		Redo:   "ctrl+r",
+	}
+
+	t.Run("keys", func(t *testing.T) {
+		tm := New(newTable("a", "b"), c)
+		tm, _ = tm.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("j")})
+		tm, _ = tm.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("d")})
+		_, cmd := tm.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("d")})
+		assertEqual(t, cmd(), tea.Msg(DeleteMsg{Heading: "foo", Name: "a", Key: "1"}))
+
+		_, cmd = tm.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("u")})
+		assertEqual(t, cmd(), tea.Msg(UndoMsg{}))
+		_, cmd = tm.Update(tea.KeyMsg{Type: tea.KeyCtrlR})
+		assertEqual(t, cmd(), tea.Msg(RedoMsg{}))
+	})
+
+	t.Run("set table", func(t *testing.T) {
+		tm := New(newTable("a", "b", "c"), c)
+		tm.table.SetFolded(tm.table.Rows[4], true)
+		tm.cursor = 3
+
+		tm.SetTable(newTable("a", "c"))
+		assertEqual(t, tm.table.Rows[3].Folded, true)
+		assertEqual(t, tm.table.Rows[1].PrefixSep, "$")
+		assertEqual(t, tm.table.SepWidth, 4)
+		assertEqual(t, tm.cursor, 3)
+
+		// cursor stays within the table
+		tm.SetTable(newTable())
+		assertEqual(t, tm.cursor, 1)
+	})
+}
@@ -0,0 +1,181 @@
+package list
+
+import (
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/charmbracelet/x/ansi"
+)
+
+// max time between the clicks of a double click
+const doubleClickTime = 400 * time.Millisecond
+
+type mouse struct {
+	// scrollbar is being dragged
+	dragging bool
+
+	// last click, to detect double clicks
+	lastClick time.Time
+	lastIndex int
+}
+
+func (m *Model) handleMouse(msg tea.MouseMsg) {
+	switch msg.Button {
+	case tea.MouseButtonWheelUp:
+		m.cursor -= m.viewport.MouseWheelDelta
+		if m.cursorPastViewTop() {
+			m.viewport.ScrollUp(m.viewport.MouseWheelDelta)
+		}
+		return
+	case tea.MouseButtonWheelDown:
+		m.cursor += m.viewport.MouseWheelDelta
+		if m.cursorPastViewBottom() {
+			m.viewport.ScrollDown(m.viewport.MouseWheelDelta)
+		}
+		return
+	}
+
+	if m.picker.active {
+		return
+	}
+
+	line := msg.Y - m.bodyTop()
+	inBody := line >= 0 && line < m.viewport.Height
+
+	switch msg.Action {
+	case tea.MouseActionPress:
+		if msg.Button != tea.MouseButtonLeft || !inBody {
+			return
+		}
+		if m.showScrollbar() && msg.X >= m.scrollbarX() {
+			m.mouse.dragging = true
+			m.dragScrollbar(line)
+			return
+		}
+		m.click(m.rowAt(msg.X, m.viewport.YOffset+line))
+
+	case tea.MouseActionMotion:
+		if m.mouse.dragging {
+			m.dragScrollbar(line)
+		}
+
+	case tea.MouseActionRelease:
+		m.mouse.dragging = false
+	}
+}
+
+// Select the clicked row. Clicking a heading folds or filters it and
+// double clicking a row copies its key
+func (m *Model) click(index int) {
+	if index < 0 || index >= m.maxRows {
+		return
+	}
+
+	now := time.Now()
+	double := index == m.mouse.lastIndex && now.Sub(m.mouse.lastClick) < doubleClickTime
+	m.mouse.lastClick, m.mouse.lastIndex = now, index
+	if double {
+		// a third click starts a new double click
+		m.mouse.lastClick = time.Time{}
+	}
+
+	m.cursor = index
+	row := m.currentRow()
+	if row == nil {
+		return
+	}
+
+	switch {
+	case row.IsHeading && m.headingClick == "filter":
+		m.ApplyQuery(headingPrefix + row.Text)
+	case row.IsHeading:
+		m.toggleFold()
+	case double:
+		m.copyRow()
+	}
+}
+
+// Scroll so the scrollbar thumb follows the mouse
+func (m *Model) dragScrollbar(line int) {
+	total, height := m.viewport.TotalLineCount(), m.viewport.Height
+	if total <= height || height <= 1 {
+		return
+	}
+
+	line = max(0, min(line, height-1))
+	m.viewport.SetYOffset(line * (total - height) / (height - 1))
+
+	if m.cursorPastViewTop() {
+		m.cursor = m.rowAtLine(m.viewport.YOffset)
+	} else if m.cursorPastViewBottom() {
+		m.cursor = m.rowAtLine(m.viewport.YOffset + height - 1)
+	}
+}
+
+// Row at screen column x and line of the rows
+func (m *Model) rowAt(x, line int) int {
+	t := m.currentTable()
+	return t.RowAt(t.ColumnAt(x-m.margin-1-m.padding), line)
+}
+
+// Screen line of the first row, below the margin, border, padding, title and
+// prompt
+func (m *Model) bodyTop() int {
+	top := m.margin + 1 + m.padding
+	if m.title != "" && m.titleLocation != "border" {
+		top++
+	}
+	if m.promptLocation != "bottom" {
+		// search bar and counter
+		top += 2
+	}
+	return top
+}
+
+// Width of text inside the border and padding
+func (m *Model) contentWidth() int {
+	return m.viewport.Width - m.padding*2
+}
+
+// Screen column of the scrollbar
+func (m *Model) scrollbarX() int {
+	return m.margin + 1 + m.padding + m.contentWidth() - 1
+}
+
+func (m *Model) showScrollbar() bool {
+	return m.scrollbar && m.contentWidth() > 1 &&
+		m.viewport.TotalLineCount() > m.viewport.Height
+}
+
+// Add scrollbar to the right of the rows
+func (m *Model) withScrollbar(body string) string {
+	if !m.showScrollbar() {
+		return body
+	}
+
+	height, total := m.viewport.Height, m.viewport.TotalLineCount()
+	thumbSize := max(1, height*height/total)
+	thumbTop := 0
+	if total > height {
+		thumbTop = m.viewport.YOffset * (height - thumbSize) / (total - height)
+	}
+
+	width := m.contentWidth() - 1
+	lines := strings.Split(body, "\n")
+	for i := range lines {
+		line := ansi.Truncate(lines[i], width, "")
+		if w := lipgloss.Width(line); w < width {
+			line += strings.Repeat(" ", width-w)
+		}
+
+		if i >= thumbTop && i < thumbTop+thumbSize {
+			line += m.scrollbarStyle.Render("┃")
+		} else {
+			line += m.scrollbarStyle.Faint(true).Render("│")
+		}
+		lines[i] = line
+	}
+	return strings.Join(lines, "\n")
+}
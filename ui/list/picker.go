@@ -0,0 +1,186 @@
+package list
+
+import (
+	"strings"
+
+	"github.com/charmbracelet/bubbles/key"
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// picker is a menu overlay for choosing one of several items
+type picker struct {
+	title  string
+	items  []pickerItem
+	cursor int
+	active bool
+
+	// filterable pickers narrow items with a fuzzy filter
+	filterable bool
+	filter     textinput.Model
+	matches    []int // indexes of items matching filter
+
+	// called with the value of the chosen item
+	choose func(m *Model, value string)
+
+	heading  lipgloss.Style
+	normal   lipgloss.Style
+	selected lipgloss.Style
+}
+
+type pickerItem struct {
+	label string
+	value string
+}
+
+func (p *picker) open(title string, items []pickerItem, filterable bool, choose func(*Model, string)) tea.Cmd {
+	p.title = title
+	p.items = items
+	p.choose = choose
+	p.cursor = 0
+	p.active = true
+	p.filterable = filterable
+
+	p.filter = textinput.New()
+	p.filter.Prompt = "> "
+	p.filterItems()
+
+	if filterable {
+		return p.filter.Focus()
+	}
+	return nil
+}
+
+func (p *picker) close() {
+	p.active = false
+	p.items = nil
+	p.matches = nil
+	p.choose = nil
+	p.filter.Blur()
+}
+
+func (p *picker) prev() {
+	if p.cursor > 0 {
+		p.cursor--
+	}
+}
+
+func (p *picker) next() {
+	if p.cursor < len(p.matches)-1 {
+		p.cursor++
+	}
+}
+
+func (p *picker) current() *pickerItem {
+	if p.cursor < 0 || p.cursor >= len(p.matches) {
+		return nil
+	}
+	return &p.items[p.matches[p.cursor]]
+}
+
+// Narrow items to those matching the filter input
+func (p *picker) filterItems() {
+	p.matches = p.matches[:0]
+	p.cursor = 0
+
+	value := strings.TrimSpace(p.filter.Value())
+	if value == "" {
+		for i := range p.items {
+			p.matches = append(p.matches, i)
+		}
+		return
+	}
+
+	labels := make([]string, len(p.items))
+	for i, item := range p.items {
+		labels[i] = item.label
+	}
+	for _, match := range filter(value, labels) {
+		p.matches = append(p.matches, match.Index)
+	}
+}
+
+func (m *Model) handlePicker(msg tea.Msg) tea.Cmd {
+
+	switch msg := msg.(type) {
+	case tea.KeyMsg:
+		switch {
+		case msg.String() == "ctrl+c":
+			return m.quit()
+
+		case key.Matches(msg, m.keys.Normal):
+			m.picker.close()
+			return nil
+
+		// arrow keys move the selection, also while typing a filter
+		case key.Matches(msg, m.keys.UpFocus), msg.Type == tea.KeyUp:
+			m.picker.prev()
+			return nil
+		case key.Matches(msg, m.keys.DownFocus), msg.Type == tea.KeyDown:
+			m.picker.next()
+			return nil
+
+		case key.Matches(msg, m.keys.Accept):
+			item, choose := m.picker.current(), m.picker.choose
+			m.picker.close()
+			if item != nil && choose != nil {
+				choose(m, item.value)
+			}
+			return nil
+		}
+
+		// remaining keys are typed into the filter of filterable pickers
+		if !m.picker.filterable {
+			switch {
+			case key.Matches(msg, m.keys.Quit):
+				m.picker.close()
+			case key.Matches(msg, m.keys.Up):
+				m.picker.prev()
+			case key.Matches(msg, m.keys.Down):
+				m.picker.next()
+			}
+			return nil
+		}
+	}
+
+	if !m.picker.filterable {
+		return nil
+	}
+
+	var cmd tea.Cmd
+	value := m.picker.filter.Value()
+	m.picker.filter, cmd = m.picker.filter.Update(msg)
+	if m.picker.filter.Value() != value {
+		m.picker.filterItems()
+	}
+	return cmd
+}
+
+func (p *picker) view(width, height int) string {
+	lines := []string{p.heading.Render(p.title)}
+	if p.filterable {
+		lines = append(lines, p.normal.Render(p.filter.View()))
+	}
+
+	// keep cursor in view
+	start := 0
+	if visible := height - len(lines); visible > 0 && p.cursor >= visible {
+		start = p.cursor - visible + 1
+	}
+
+	for i := start; i < len(p.matches); i++ {
+		label := p.items[p.matches[i]].label
+		if i == p.cursor {
+			lines = append(lines, p.selected.Render(label))
+		} else {
+			lines = append(lines, p.normal.Render(label))
+		}
+	}
+
+	return lipgloss.NewStyle().
+		Width(width).
+		Height(height).
+		MaxHeight(height).
+		Render(strings.Join(lines, "\n"))
+}
@@ -0,0 +1,172 @@
+package list
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// sequence tracks multi-key sequences and count prefixes typed in normal mode
+type sequence struct {
+	pending []string
+	count   int
+	timeout time.Duration
+
+	// binding to dispatch if the pending sequence times out
+	exact string
+	// identifies the pending sequence so stale timeouts are ignored
+	id int
+}
+
+type sequenceTimeoutMsg struct {
+	id int
+}
+
+// key names that are longer than one character
+var namedKeys = map[string]bool{
+	"tab": true, "enter": true, "esc": true, "backspace": true,
+	"up": true, "down": true, "left": true, "right": true,
+	"home": true, "end": true, "pgup": true, "pgdown": true,
+	"delete": true, "insert": true,
+}
+
+// Split a binding into its keys. Keys are separated by spaces (e.g. "ctrl+w
+// j") while runs of plain characters are one key per character (e.g. "gg")
+func parseSequence(s string) []string {
+	if strings.Contains(s, " ") {
+		return strings.Fields(s)
+	}
+
+	runes := []rune(s)
+	if len(runes) <= 1 || strings.Contains(s, "+") || namedKeys[s] || isFunctionKey(s) {
+		return []string{s}
+	}
+
+	keys := make([]string, len(runes))
+	for i, r := range runes {
+		keys[i] = string(r)
+	}
+	return keys
+}
+
+func isFunctionKey(s string) bool {
+	var n int
+	_, err := fmt.Sscanf(s, "f%d", &n)
+	return err == nil
+}
+
+// Returns the binding that keys match exactly and whether keys are the start
+// of a longer binding
+func (m *Model) matchSequence(keys []string) (exact string, prefix bool) {
+	for _, b := range m.keys.normalBindings() {
+		for _, k := range b.Keys() {
+			seq := parseSequence(k)
+			switch {
+			case slices.Equal(seq, keys):
+				exact = k
+			case len(seq) > len(keys) && slices.Equal(seq[:len(keys)], keys):
+				prefix = true
+			}
+		}
+	}
+	return exact, prefix
+}
+
+// Resolve key presses into bindings. Returns the message to be handled in
+// normal mode, or nil while a sequence or count is still being typed
+func (m *Model) dispatchSequence(msg tea.Msg) (tea.Msg, tea.Cmd) {
+	switch msg := msg.(type) {
+	case sequenceTimeoutMsg:
+		if msg.id != m.seq.id || len(m.seq.pending) == 0 {
+			return nil, nil
+		}
+
+		exact := m.seq.exact
+		m.seq.reset()
+		if exact == "" {
+			m.seq.count = 0
+			return nil, nil
+		}
+		return sequenceKey(exact), nil
+
+	case tea.KeyMsg:
+		k := msg.String()
+
+		if len(m.seq.pending) == 0 && m.isCount(k) {
+			m.seq.count = m.seq.count*10 + int(k[0]-'0')
+			return nil, nil
+		}
+
+		keys := append(slices.Clone(m.seq.pending), k)
+		exact, prefix := m.matchSequence(keys)
+
+		switch {
+		case prefix:
+			// wait for the rest of the sequence
+			m.seq.pending = keys
+			m.seq.exact = exact
+			m.seq.id++
+
+			id := m.seq.id
+			return nil, tea.Tick(m.seq.timeout, func(time.Time) tea.Msg {
+				return sequenceTimeoutMsg{id}
+			})
+
+		case exact != "" && len(keys) > 1:
+			m.seq.reset()
+			return sequenceKey(exact), nil
+
+		case len(m.seq.pending) > 0:
+			// abandon the pending sequence and retry the key on its own
+			m.seq.reset()
+			return m.dispatchSequence(msg)
+		}
+	}
+	return msg, nil
+}
+
+// Digits start or continue a count unless they are bound on their own
+func (m *Model) isCount(k string) bool {
+	if len(k) != 1 || k[0] < '0' || k[0] > '9' {
+		return false
+	}
+	if k == "0" && m.seq.count == 0 {
+		return false
+	}
+
+	exact, prefix := m.matchSequence([]string{k})
+	return exact == "" && !prefix
+}
+
+// Key message matching a multi-key binding, so it is handled like any other
+// key press
+func sequenceKey(binding string) tea.KeyMsg {
+	return tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune(binding)}
+}
+
+func (s *sequence) reset() {
+	s.pending = nil
+	s.exact = ""
+}
+
+// Returns the typed count, or 0 if none was typed, and clears it
+func (s *sequence) takeCount() int {
+	n := s.count
+	s.count = 0
+	return n
+}
+
+// Keys typed so far, shown while waiting for a sequence to complete
+func (s *sequence) String() string {
+	var sb strings.Builder
+	if s.count > 0 {
+		fmt.Fprintf(&sb, "%d", s.count)
+	}
+	for _, k := range s.pending {
+		sb.WriteString(k)
+	}
+	return sb.String()
+}
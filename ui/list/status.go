@@ -0,0 +1,199 @@
+package list
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/charmbracelet/x/ansi"
+)
+
+// Status line template with its left, center and right segments. Segments
+// may contain the placeholders:
+//
+//	{filtered} number of rows shown
+//	{total}    number of rows
+//	{heading}  heading of the selected row
+//	{mode}     normal, search or filter
+//	{matcher}  fuzzy, or heading when filtering by heading
+//	{file}     keyb file path
+//	{reload}   pending or failed reload of the keyb file
+//	{status}   pending keys, messages, reloads and searches in flight
+type statusLine struct {
+	left   string
+	center string
+	right  string
+}
+
+// State of reloading the list after the keyb file was edited
+type ReloadState int
+
+const (
+	// list shows the keyb file on disk
+	ReloadDone ReloadState = iota
+	// list shows edits that are not written yet
+	ReloadPending
+	// edits could not be written and the list shows the keyb file on disk
+	ReloadFailed
+)
+
+func (r ReloadState) String() string {
+	switch r {
+	case ReloadPending:
+		return "reload pending"
+	case ReloadFailed:
+		return "reload failed"
+	default:
+		return ""
+	}
+}
+
+// Show state of reloading the keyb file in the status line
+func (m *Model) SetReload(r ReloadState) {
+	m.reload = r
+}
+
+// Expand placeholders in a status line segment
+func (m *Model) expandStatus(segment string) string {
+	if segment == "" {
+		return ""
+	}
+
+	filtered := m.table.LineCount
+	if m.filterState == filtering && m.searchBar.Value() != "" {
+		filtered = m.filteredTable.LineCount
+	}
+
+	r := strings.NewReplacer(
+		"{filtered}", strconv.Itoa(filtered),
+		"{total}", strconv.Itoa(m.table.LineCount),
+		"{heading}", m.currentHeading,
+		"{mode}", m.mode(),
+		"{matcher}", m.matcher(),
+		"{file}", shortenPath(m.file),
+		"{reload}", m.reload.String(),
+		"{status}", m.status(),
+	)
+	return r.Replace(segment)
+}
+
+func (m *Model) mode() string {
+	switch {
+	case m.searchMode():
+		return "search"
+	case m.filterState == filtering:
+		return "filter"
+	default:
+		return "normal"
+	}
+}
+
+func (m *Model) matcher() string {
+	if strings.HasPrefix(m.searchBar.Value(), headingPrefix) {
+		return "heading"
+	}
+	return "fuzzy"
+}
+
+// Pending keys of a sequence, messages from the last action and whether a
+// query or reload is in flight
+func (m *Model) status() string {
+	var s []string
+	if m.searching {
+		s = append(s, "searching…")
+	}
+	if m.reload != ReloadDone {
+		s = append(s, m.reload.String())
+	}
+	if pending := m.seq.String(); pending != "" {
+		s = append(s, pending)
+	}
+	if m.message != "" {
+		s = append(s, m.message)
+	}
+	return strings.Join(s, "  ")
+}
+
+// Replace home directory with ~
+func shortenPath(path string) string {
+	home, err := os.UserHomeDir()
+	if err != nil || home == "" {
+		return path
+	}
+	if rel, err := filepath.Rel(home, path); err == nil && !strings.HasPrefix(rel, "..") {
+		return filepath.Join("~", rel)
+	}
+	return path
+}
+
+func formCounter(m *Model) string {
+	left := m.expandStatus(m.statusLine.left)
+	center := m.expandStatus(m.statusLine.center)
+	right := m.expandStatus(m.statusLine.right)
+
+	if m.debug {
+		left = fmt.Sprintf("%s  Line: %d YOffset: %d Height: %d",
+			left, m.cursor, m.viewport.YOffset, m.viewport.Height)
+	}
+
+	// counter has a margin of 1 on each side
+	width := m.viewport.Width - m.padding*2 - 2
+	return m.counterStyle.Render(alignStatus(left, center, right, width))
+}
+
+// Place left, center and right segments across width. Segments are joined
+// and truncated if they do not fit
+func alignStatus(left, center, right string, width int) string {
+	if center == "" && right == "" {
+		return left
+	}
+
+	lw, cw, rw := lipgloss.Width(left), lipgloss.Width(center), lipgloss.Width(right)
+	if width <= 0 || lw+cw+rw+2 > width {
+		var segments []string
+		for _, s := range []string{left, center, right} {
+			if s != "" {
+				segments = append(segments, s)
+			}
+		}
+		line := strings.Join(segments, " ")
+		if width > 0 {
+			line = ansi.Truncate(line, width, "")
+		}
+		return line
+	}
+
+	line := left
+	if center != "" {
+		start := max((width-cw)/2, lw+1)
+		line += strings.Repeat(" ", start-lw) + center
+	}
+	if right != "" {
+		gap := max(1, width-lipgloss.Width(line)-rw)
+		line += strings.Repeat(" ", gap) + right
+	}
+	return line
+}
+
+// Draw title over the top border of a rendered box
+func (m *Model) borderTitle(box string) string {
+	lines := strings.Split(box, "\n")
+	inner := lipgloss.Width(lines[0]) - 2
+	if m.title == "" || inner < 3 {
+		return box
+	}
+
+	b := m.border.GetBorderStyle()
+	style := lipgloss.NewStyle().Foreground(m.border.GetBorderTopForeground())
+
+	title := ansi.Truncate(" "+m.title+" ", inner-1, "")
+	fill := inner - 1 - lipgloss.Width(title)
+
+	lines[0] = style.Render(b.TopLeft+b.Top) +
+		m.titleStyle.Render(title) +
+		style.Render(strings.Repeat(b.Top, fill)+b.TopRight)
+	return strings.Join(lines, "\n")
+}
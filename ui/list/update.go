@@ -1,20 +1,19 @@
 package list
 
 import (
-	"sort"
-	"strings"
-
 	"github.com/charmbracelet/bubbles/key"
 	tea "github.com/charmbracelet/bubbletea"
-	"github.com/kencx/keyb/ui/table"
-	"github.com/sahilm/fuzzy"
 )
 
 func (m Model) Update(msg tea.Msg) (Model, tea.Cmd) {
 
 	var cmds []tea.Cmd
+	prevCursor := m.cursor
 
 	switch msg := msg.(type) {
+	case tea.KeyMsg:
+		m.message = ""
+
 	case tea.WindowSizeMsg:
 
 		// to play nice with borders and truncation,
@@ -22,36 +21,36 @@ func (m Model) Update(msg tea.Msg) (Model, tea.Cmd) {
 		m.viewport.Width = msg.Width - max(2, (m.padding*2+m.margin*2))
 		m.viewport.Height = msg.Height - m.scrollOffset
 
-		m.table.MaxWidth = m.viewport.Width - m.padding*2
-		m.filteredTable.MaxWidth = m.viewport.Width - m.padding*2
+		width := m.contentWidth()
+		if m.wrap && m.scrollbar {
+			// wrapped lines leave room for the scrollbar
+			width--
+		}
+		m.table.MaxWidth = width
+		m.filteredTable.MaxWidth = width
 
 		if m.cursorPastViewBottom() {
-			m.cursor = m.viewport.YOffset + m.viewport.Height - 1
+			m.cursor = m.rowAtLine(m.viewport.YOffset + m.viewport.Height - 1)
 		}
 
 	case tea.MouseMsg:
 		if !m.viewport.MouseWheelEnabled {
 			break
 		}
-		switch msg.Button {
-		case tea.MouseButtonWheelUp:
-			m.cursor -= m.viewport.MouseWheelDelta
-			if m.cursorPastViewTop() {
-				m.viewport.ScrollUp(m.viewport.MouseWheelDelta)
-			}
-		case tea.MouseButtonWheelDown:
-			m.cursor += m.viewport.MouseWheelDelta
-			if m.cursorPastViewBottom() {
-				m.viewport.ScrollDown(m.viewport.MouseWheelDelta)
-			}
-		}
+		m.handleMouse(msg)
 	}
 
+	result, isResult := msg.(filterMsg)
 	switch {
+	case isResult:
+		m.showMatches(result)
+	case m.picker.active:
+		cmds = append(cmds, m.handlePicker(msg))
 	case m.searchMode():
 		cmds = append(cmds, m.handleSearch(msg))
 	default:
-		cmds = append(cmds, m.handleNormal(msg))
+		msg, cmd := m.dispatchSequence(msg)
+		cmds = append(cmds, cmd, m.handleNormal(msg))
 	}
 
 	// cursor loop around
@@ -63,7 +62,20 @@ func (m Model) Update(msg tea.Msg) (Model, tea.Cmd) {
 		m.viewport.GotoTop()
 	}
 
+	if m.skipHeadings {
+		m.skipHeadingRow(m.cursor - prevCursor)
+	}
+
 	m.visibleRows()
+
+	// grid layout is only known after rendering
+	if m.grid {
+		m.scrollToCursor()
+	}
+
+	// filtering started outside of Update
+	cmds = append(cmds, m.pendingFilter)
+	m.pendingFilter = nil
 	return m, tea.Batch(cmds...)
 }
 
@@ -71,9 +83,12 @@ func (m *Model) handleNormal(msg tea.Msg) tea.Cmd {
 
 	switch msg := msg.(type) {
 	case tea.KeyMsg:
+		count := m.seq.takeCount()
+		n := max(1, count)
+
 		switch {
 		case key.Matches(msg, m.keys.Quit):
-			return tea.Quit
+			return m.quit()
 
 		case key.Matches(msg, m.keys.Search):
 			return m.startSearch()
@@ -82,30 +97,42 @@ func (m *Model) handleNormal(msg tea.Msg) tea.Cmd {
 			m.searchBar.Reset()
 			return m.startSearch()
 
-		case key.Matches(msg, m.keys.Up):
-			m.cursor--
-			if m.cursorPastViewTop() {
-				m.viewport.ScrollUp(1)
-			}
-		case key.Matches(msg, m.keys.Down):
-			m.cursor++
-			if m.cursorPastViewBottom() {
-				m.viewport.ScrollDown(1)
-			}
-
-		case key.Matches(msg, m.keys.UpFocus):
-			m.cursor--
-			if m.cursorPastViewTop() {
-				m.viewport.ScrollUp(1)
-			}
-		case key.Matches(msg, m.keys.DownFocus):
-			m.cursor++
-			if m.cursorPastViewBottom() {
-				m.viewport.ScrollDown(1)
-			}
+		case key.Matches(msg, m.keys.SavedQueries):
+			m.openSavedQueries()
+
+		case key.Matches(msg, m.keys.Pin):
+			m.togglePin()
+		case key.Matches(msg, m.keys.Copy):
+			m.copyRow()
+		case key.Matches(msg, m.keys.Accept):
+			m.selectRow()
+
+		case key.Matches(msg, m.keys.Delete):
+			return m.deleteRow()
+		case key.Matches(msg, m.keys.Undo):
+			return undo
+		case key.Matches(msg, m.keys.Redo):
+			return redo
+
+		case key.Matches(msg, m.keys.Fold):
+			m.toggleFold()
+		case key.Matches(msg, m.keys.CollapseAll):
+			m.foldAll(true)
+		case key.Matches(msg, m.keys.ExpandAll):
+			m.foldAll(false)
+
+		case key.Matches(msg, m.keys.Left):
+			m.cursorToColumn(-n)
+		case key.Matches(msg, m.keys.Right):
+			m.cursorToColumn(n)
+
+		case key.Matches(msg, m.keys.Up, m.keys.UpFocus):
+			m.cursorUp(n)
+		case key.Matches(msg, m.keys.Down, m.keys.DownFocus):
+			m.cursorDown(n)
 
 		case key.Matches(msg, m.keys.HalfUp):
-			m.cursor -= m.viewport.Height / 2
+			m.cursor -= n * m.viewport.Height / 2
 			if m.cursorPastViewTop() {
 				m.viewport.HalfPageUp()
 			}
@@ -116,7 +143,7 @@ func (m *Model) handleNormal(msg tea.Msg) tea.Cmd {
 				m.viewport.GotoTop()
 			}
 		case key.Matches(msg, m.keys.HalfDown):
-			m.cursor += m.viewport.Height / 2
+			m.cursor += n * m.viewport.Height / 2
 			if m.cursorPastViewBottom() {
 				m.viewport.HalfPageDown()
 			}
@@ -128,7 +155,7 @@ func (m *Model) handleNormal(msg tea.Msg) tea.Cmd {
 			}
 
 		case key.Matches(msg, m.keys.FullUp):
-			m.cursor -= m.viewport.Height
+			m.cursor -= n * m.viewport.Height
 			if m.cursorPastViewTop() {
 				m.viewport.PageUp()
 			}
@@ -140,7 +167,7 @@ func (m *Model) handleNormal(msg tea.Msg) tea.Cmd {
 			}
 
 		case key.Matches(msg, m.keys.FullDown):
-			m.cursor += m.viewport.Height
+			m.cursor += n * m.viewport.Height
 			if m.cursorPastViewBottom() {
 				m.viewport.PageDown()
 			}
@@ -151,6 +178,11 @@ func (m *Model) handleNormal(msg tea.Msg) tea.Cmd {
 				m.viewport.GotoBottom()
 			}
 
+		case key.Matches(msg, m.keys.GoToFirstLine, m.keys.GoToLastLine) && count > 0:
+			// go to line given by count
+			m.cursor = min(count, m.maxRows) - 1
+			m.scrollToCursor()
+
 		case key.Matches(msg, m.keys.GoToFirstLine):
 			m.cursorToBeginning()
 			m.viewport.GotoTop()
@@ -167,14 +199,22 @@ func (m *Model) handleNormal(msg tea.Msg) tea.Cmd {
 		case key.Matches(msg, m.keys.GoToBottom):
 			m.cursorToViewBottom()
 
-			// case key.Matches(msg, m.keys.CenterCursor):
-			// 	middle := m.viewport.Height / 2
-			// 	diff := m.cursor - middle
-			// 	if diff > 0 {
-			// 		m.viewport.LineDown(diff)
-			// 	} else {
-			// 		m.viewport.LineUp(diff)
-			// 	}
+		case key.Matches(msg, m.keys.NextHeading):
+			for range n {
+				m.nextHeading()
+			}
+		case key.Matches(msg, m.keys.PrevHeading):
+			for range n {
+				m.prevHeading()
+			}
+		case key.Matches(msg, m.keys.AppPicker):
+			return m.openAppPicker()
+
+		case key.Matches(msg, m.keys.CenterCursor):
+			m.viewport.SetYOffset(m.cursorLine() - m.viewport.Height/2)
+
+		case key.Matches(msg, m.keys.Help):
+			return showHelp
 		}
 	}
 	return nil
@@ -191,24 +231,29 @@ func (m *Model) handleSearch(msg tea.Msg) tea.Cmd {
 	case tea.KeyMsg:
 		switch {
 		case msg.String() == "ctrl+c":
-			return tea.Quit
+			return m.quit()
 
 		case key.Matches(msg, m.keys.ClearSearch):
 			m.searchBar.Reset()
+			m.historyIndex = -1
 			return m.startSearch()
 
+		case key.Matches(msg, m.keys.HistoryPrev):
+			m.historyPrev()
+			return m.filterRows()
+		case key.Matches(msg, m.keys.HistoryNext):
+			m.historyNext()
+			return m.filterRows()
+		case key.Matches(msg, m.keys.HistorySearch):
+			m.historySearch()
+			return m.filterRows()
+
 			// scrolling in search mode
 		case key.Matches(msg, m.keys.UpFocus):
-			m.cursor--
-			if m.cursorPastViewTop() {
-				m.viewport.ScrollUp(1)
-			}
+			m.cursorUp(1)
 			return nil
 		case key.Matches(msg, m.keys.DownFocus):
-			m.cursor++
-			if m.cursorPastViewBottom() {
-				m.viewport.ScrollDown(1)
-			}
+			m.cursorDown(1)
 			return nil
 
 		case key.Matches(msg, m.keys.HalfUp):
@@ -262,92 +307,34 @@ func (m *Model) handleSearch(msg tea.Msg) tea.Cmd {
 			m.search = false
 			m.searchBar.Blur()
 
+			if m.filteredTable.Empty() {
+				m.filterState = unfiltered
+			}
+			return nil
+
+		case key.Matches(msg, m.keys.Accept):
+			m.search = false
+			m.searchBar.Blur()
+			m.recordQuery()
+
 			if m.filteredTable.Empty() {
 				m.filterState = unfiltered
 			}
 			return nil
 		}
+
+		// editing the query ends history browsing
+		m.historyIndex = -1
 	}
 
-	// filter with search input
+	// filter with search input. Messages such as cursor blinks leave the
+	// query unchanged and must not cancel a search in flight
+	query := m.searchBar.Value()
 	m.searchBar, cmd = m.searchBar.Update(msg)
 	cmds = append(cmds, cmd)
 
-	prefix := "h:"
-	if strings.HasPrefix(m.searchBar.Value(), prefix) {
-		matchHeadings(m, prefix)
-	} else {
-		matchRows(m)
-	}
-
-	// reset if search input is empty regardless of filterState
-	if m.searchBar.Value() == "" {
-		m.Reset()
-
-		// remain in filtering state
-		// until user explicitly returns to Normal mode
-		m.filterState = filtering
+	if m.searchBar.Value() != query {
+		cmds = append(cmds, m.filterRows())
 	}
 	return tea.Batch(cmds...)
 }
-
-func filter(term string, target []string) fuzzy.Matches {
-	matches := fuzzy.Find(term, target)
-	sort.Stable(matches)
-	return matches
-}
-
-func matchHeadings(m *Model, prefix string) {
-	var matches fuzzy.Matches
-	value := strings.TrimSpace(strings.TrimPrefix(m.searchBar.Value(), prefix))
-	if !m.table.Empty() {
-		matches = filter(value, m.table.GetPlainHeadings())
-	}
-
-	// present new filtered rows
-	m.filteredTable.Reset()
-	if len(matches) == 0 {
-		m.filteredTable.AppendRow(table.EmptyRow())
-
-	} else {
-		var hlMatches []*table.Row
-		// get non-pointers as filtering is ephemeral
-		headings := m.table.GetCopyOfHeadings()
-
-		for _, match := range matches {
-			heading := headings[match.Index]
-			heading.IsFiltered = true
-			heading.MatchedIndex = match.MatchedIndexes
-
-			hlMatches = append(hlMatches, &heading)
-			hlMatches = append(hlMatches, m.table.GetAllRowsofHeading(heading.Text)...)
-		}
-		m.filteredTable.AppendRows(hlMatches...)
-	}
-}
-
-func matchRows(m *Model) {
-	var matches fuzzy.Matches
-	if !m.table.Empty() {
-		matches = filter(m.searchBar.Value(), m.table.GetPlainRowsWithoutHeadings())
-	}
-
-	// present new filtered rows
-	m.filteredTable.Reset()
-	if len(matches) == 0 {
-		m.filteredTable.AppendRow(table.EmptyRow())
-
-	} else {
-		var hlMatches []*table.Row
-		// get non-pointers as filtering is ephemeral
-		rows := m.table.GetCopyOfRowsWithoutHeadings()
-
-		for _, match := range matches {
-			row := rows[match.Index]
-			row.IsFiltered = true
-			row.MatchedIndex = match.MatchedIndexes
-			hlMatches = append(hlMatches, &row)
-		}
-		m.filteredTable.AppendRows(hlMatches...)
-	}
-}
@@ -1,8 +1,6 @@
 package list
 
 import (
-	"fmt"
-
 	"github.com/charmbracelet/lipgloss"
 )
 
@@ -13,42 +11,35 @@ func (m *Model) View() string {
 
 	counter := formCounter(m)
 
-	var view string
+	body := m.withScrollbar(m.viewport.View())
+	if m.picker.active {
+		body = m.picker.view(m.viewport.Width, m.viewport.Height)
+	}
+
+	var view []string
+	if m.title != "" && m.titleLocation != "border" {
+		view = append(view, m.titleStyle.Margin(0, 1).Render(m.title))
+	}
+
 	if m.promptLocation == "bottom" {
-		view = lipgloss.JoinVertical(
-			lipgloss.Left,
-			m.viewport.View(),
-			counter,
-			m.searchBar.View(),
-		)
+		view = append(view, body, counter, m.searchBar.View())
 	} else {
-		view = lipgloss.JoinVertical(
-			lipgloss.Left,
-			m.searchBar.View(),
-			counter,
-			m.viewport.View(),
-		)
+		view = append(view, m.searchBar.View(), counter, body)
+	}
+
+	if m.showHelp {
+		// help has a margin of 1 on each side
+		m.shortHelp.Width = m.viewport.Width - m.padding*2 - 2
+		view = append(view, lipgloss.NewStyle().Margin(0, 1).Render(m.shortHelp.ShortHelpView(m.keys.ShortHelp())))
 	}
 
 	style := m.border.
-		Margin(m.margin).
 		Padding(m.padding).
 		Width(m.viewport.Width)
-	return style.Render(view)
-}
-
-func formCounter(m *Model) string {
-	var counter string
-
-	if m.filterState == filtering && m.searchBar.Value() != "" {
-		counter = fmt.Sprintf("%d/%d %s", m.filteredTable.LineCount, m.table.LineCount, m.currentHeading)
-	} else {
-		counter = fmt.Sprintf("%d/%d %s", m.table.LineCount, m.table.LineCount, m.currentHeading)
-	}
+	box := style.Render(lipgloss.JoinVertical(lipgloss.Left, view...))
 
-	if m.debug {
-		counter = fmt.Sprintf("%s\tLine: %d YOffset: %d Height: %d",
-			counter, m.cursor, m.viewport.YOffset, m.viewport.Height)
+	if m.titleLocation == "border" {
+		box = m.borderTitle(box)
 	}
-	return m.counterStyle.Render(counter)
+	return lipgloss.NewStyle().Margin(m.margin).Render(box)
 }
@@ -0,0 +1,99 @@
+package list
+
+import (
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+)
+
+// Scrollable view of the rendered rows. Lines are rendered on demand, so only
+// the lines in view are rendered however many rows there are
+type viewport struct {
+	Width   int
+	Height  int
+	YOffset int
+
+	MouseWheelDelta   int
+	MouseWheelEnabled bool
+
+	total  int
+	render func(from, to int) []string
+}
+
+// Show static content
+func (v *viewport) SetContent(s string) {
+	lines := strings.Split(s, "\n")
+	v.SetLines(len(lines), func(from, to int) []string {
+		return lines[from:to]
+	})
+}
+
+// Show total lines, rendered by render when in view
+func (v *viewport) SetLines(total int, render func(from, to int) []string) {
+	v.total, v.render = total, render
+	if v.YOffset > total-1 {
+		v.GotoBottom()
+	}
+}
+
+func (v *viewport) TotalLineCount() int {
+	return v.total
+}
+
+func (v *viewport) maxYOffset() int {
+	return max(0, v.total-v.Height)
+}
+
+func (v *viewport) SetYOffset(n int) {
+	v.YOffset = max(0, min(n, v.maxYOffset()))
+}
+
+func (v *viewport) ScrollDown(n int) {
+	v.SetYOffset(v.YOffset + n)
+}
+
+func (v *viewport) ScrollUp(n int) {
+	v.SetYOffset(v.YOffset - n)
+}
+
+func (v *viewport) PageDown() {
+	v.ScrollDown(v.Height)
+}
+
+func (v *viewport) PageUp() {
+	v.ScrollUp(v.Height)
+}
+
+func (v *viewport) HalfPageDown() {
+	v.ScrollDown(v.Height / 2)
+}
+
+func (v *viewport) HalfPageUp() {
+	v.ScrollUp(v.Height / 2)
+}
+
+func (v *viewport) GotoTop() {
+	v.SetYOffset(0)
+}
+
+func (v *viewport) GotoBottom() {
+	v.SetYOffset(v.maxYOffset())
+}
+
+// Lines in view, padded to the size of the viewport
+func (v *viewport) View() string {
+	var lines []string
+	if v.render != nil {
+		top := max(0, v.YOffset)
+		if bottom := min(v.total, top+v.Height); top < bottom {
+			lines = v.render(top, bottom)
+		}
+	}
+
+	return lipgloss.NewStyle().
+		Width(v.Width).
+		Height(v.Height).
+		MaxHeight(v.Height).
+		MaxWidth(v.Width).
+		Render(strings.Join(lines, "\n"))
+}
@@ -0,0 +1,264 @@
+package table
+
+import (
+	"github.com/charmbracelet/x/ansi"
+)
+
+const (
+	// Columns are at least minCellWidth wide, like a tabwriter
+	minCellWidth = 8
+	// wrapped columns are not narrowed below minWrapWidth
+	minWrapWidth = 10
+	// indentation of wrapped lines
+	wrapIndent = 2
+)
+
+// Widths of aligned columns shared by rows
+type Layout struct {
+	widths []int
+	// spaces between columns
+	padding int
+	// width cells of each column are wrapped at, 0 if not wrapped
+	limits []int
+}
+
+// Layout fitting the cells of rows. Cells followed by another cell take the
+// width of their widest cell and the padding. The last cell of a row is not
+// padded
+func newLayout(rows []*Row, padding int, cells func(*Row) []string) Layout {
+	l := Layout{padding: padding}
+	for _, r := range rows {
+		cs := cells(r)
+		for i, c := range cs {
+			if i >= len(l.widths) {
+				l.widths = append(l.widths, 0)
+			}
+
+			w := truncatedWidth(c, r.column(i).MaxWidth)
+			if i < len(cs)-1 {
+				w = max(minCellWidth, w+padding)
+			}
+			l.widths[i] = max(l.widths[i], w)
+		}
+	}
+	return l
+}
+
+// Narrow name and key columns until rows fit width, wrapping their cells
+func (l *Layout) wrap(rows []*Row, width int) {
+	var (
+		cols  []Column
+		total int
+	)
+	for _, r := range rows {
+		s := r.Styles
+		total = max(total, s.Normal.GetHorizontalFrameSize(), s.Selected.GetHorizontalFrameSize())
+		if !r.IsHeading && cols == nil {
+			cols = r.columns()
+		}
+	}
+	for _, w := range l.widths {
+		total += w
+	}
+
+	n := len(cols)
+	l.limits = make([]int, len(l.widths))
+	for total > width {
+		widest := -1
+		for i, c := range cols {
+			if i >= len(l.widths) || (c.Field != FieldName && c.Field != FieldKey) {
+				continue
+			}
+			if l.content(i, n) <= minWrapWidth {
+				continue
+			}
+			if widest < 0 || l.content(i, n) > l.content(widest, n) {
+				widest = i
+			}
+		}
+		if widest < 0 {
+			return
+		}
+
+		l.widths[widest]--
+		l.limits[widest] = l.content(widest, n)
+		total--
+	}
+}
+
+// Width of the text of column i of n, without padding
+func (l Layout) content(i, n int) int {
+	if i < n-1 {
+		return l.widths[i] - l.padding
+	}
+	return l.widths[i]
+}
+
+// Width cells of column i are wrapped at, 0 if not wrapped
+func (l Layout) limit(i int) int {
+	if i >= len(l.limits) {
+		return 0
+	}
+	return l.limits[i]
+}
+
+// Spaces before and after cell i of n cells, of width w
+func (l Layout) pad(i, n, w int, align Align) (before, after int) {
+	if i >= len(l.widths) {
+		return 0, 0
+	}
+
+	last := i == n-1
+	space := l.widths[i] - w
+	if !last {
+		space -= l.padding
+	}
+	space = max(0, space)
+
+	switch align {
+	case AlignRight:
+		before = space
+	case AlignCenter:
+		before = space / 2
+		after = space - before
+	default:
+		after = space
+	}
+
+	if last {
+		return before, 0
+	}
+	return before, after + l.padding
+}
+
+// Display width of s. Plain ASCII, most key bindings, is measured without
+// splitting into graphemes
+func textWidth(s string) int {
+	for i := 0; i < len(s); i++ {
+		if s[i] < 0x20 || s[i] >= 0x7f {
+			return ansi.StringWidth(s)
+		}
+	}
+	return len(s)
+}
+
+// Display width of cell c once truncated to maxWidth
+func truncatedWidth(c string, maxWidth int) int {
+	w := textWidth(c)
+	if maxWidth <= 0 || w <= maxWidth {
+		return w
+	}
+	text, _, tail := truncateCell(c, maxWidth)
+	return textWidth(text + tail)
+}
+
+// Grapheme cluster of a cell
+type grapheme struct {
+	// byte offset in the cell
+	start int
+	size  int
+	width int
+}
+
+// Grapheme clusters of s with their display widths
+func graphemes(s string) []grapheme {
+	var res []grapheme
+	for i := 0; i < len(s); {
+		cluster, w := ansi.FirstGraphemeCluster(s[i:], ansi.GraphemeWidth)
+		if cluster == "" {
+			break
+		}
+		res = append(res, grapheme{start: i, size: len(cluster), width: w})
+		i += len(cluster)
+	}
+	return res
+}
+
+// Display width of graphemes from to to
+func rangeWidth(gs []grapheme, from, to int) int {
+	var w int
+	for _, g := range gs[from:to] {
+		w += g.width
+	}
+	return w
+}
+
+// Truncate cell to maxWidth, returning the kept text, its number of graphemes
+// and the tail to append
+func truncateCell(c string, maxWidth int) (string, int, string) {
+	gs := graphemes(c)
+	if maxWidth <= 0 || rangeWidth(gs, 0, len(gs)) <= maxWidth {
+		return c, len(gs), ""
+	}
+
+	tail := "..."
+	avail := maxWidth - len(tail)
+	if avail < 0 {
+		tail, avail = "", maxWidth
+	}
+
+	var w, keep int
+	for _, g := range gs {
+		w += g.width
+		if w > avail {
+			break
+		}
+		keep++
+	}
+
+	end := len(c)
+	if keep < len(gs) {
+		end = gs[keep].start
+	}
+	return c[:end], keep, tail
+}
+
+// Grapheme ranges of the lines of c word wrapped to width. Lines after the
+// first are indented by wrapIndent
+func wrapCell(c string, width int) [][2]int {
+	gs := graphemes(c)
+	if width <= wrapIndent || rangeWidth(gs, 0, len(gs)) <= width {
+		return [][2]int{{0, len(gs)}}
+	}
+	space := func(i int) bool {
+		return c[gs[i].start:gs[i].start+gs[i].size] == " "
+	}
+
+	var (
+		res   [][2]int
+		start int
+	)
+	for start < len(gs) {
+		avail := width
+		if len(res) > 0 {
+			avail -= wrapIndent
+		}
+
+		// graphemes that fit
+		end, w := start, 0
+		for end < len(gs) && w+gs[end].width <= avail {
+			w += gs[end].width
+			end++
+		}
+		end = max(end, start+1)
+
+		next := end
+		if end < len(gs) {
+			// break at the last space, unless the word is longer than a line
+			for i := end; i > start; i-- {
+				if space(i) {
+					end, next = i, i
+					break
+				}
+			}
+		}
+		res = append(res, [2]int{start, end})
+
+		// wrapped lines do not start with spaces
+		for next < len(gs) && space(next) {
+			next++
+		}
+		start = next
+	}
+	return res
+}
@@ -0,0 +1,52 @@
+package table
+
+import (
+	"fmt"
+	"testing"
+)
+
+var benchSizes = []int{1000, 10000, 50000}
+
+func benchTable(n int) *Model {
+	rows := make([]*Row, 0, n+n/20)
+	for i := range n {
+		heading := fmt.Sprintf("app %d", i/20)
+		if i%20 == 0 {
+			rows = append(rows, NewHeading(heading))
+		}
+		rows = append(rows, NewRow(fmt.Sprintf("command number %d", i), fmt.Sprintf("ctrl+%d", i%10), "", heading))
+	}
+	t := New(rows)
+	t.SepWidth = 4
+	t.MaxWidth = 80
+	return t
+}
+
+// Rendering the rows in view stays flat as the table grows
+func BenchmarkRenderLines(b *testing.B) {
+	for _, n := range benchSizes {
+		b.Run(fmt.Sprint(n), func(b *testing.B) {
+			t := benchTable(n)
+			t.RenderLines(0, 40)
+
+			b.ResetTimer()
+			for i := range b.N {
+				t.Select(i % n)
+				t.RenderLines(n/2, n/2+40)
+			}
+		})
+	}
+}
+
+// Rebuilding the layout after the table changes, once per change
+func BenchmarkLayout(b *testing.B) {
+	for _, n := range benchSizes {
+		b.Run(fmt.Sprint(n), func(b *testing.B) {
+			t := benchTable(n)
+			for range b.N {
+				t.Invalidate()
+				t.TotalLines()
+			}
+		})
+	}
+}
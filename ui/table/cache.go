@@ -0,0 +1,116 @@
+package table
+
+import "strings"
+
+// Visible rows, column widths and line positions, kept until the table
+// changes so that rendering a window of rows does not walk the whole table
+type cache struct {
+	valid bool
+
+	// settings the cache was built with
+	rowCount int
+	maxWidth int
+	sepWidth int
+	wrap     bool
+	grid     bool
+	columns  int
+
+	visible []*Row
+	// rows with text and their index among visible rows
+	rows  []*Row
+	index []int
+
+	layout Layout
+	// first line of each row and the total number of lines
+	starts []int
+	lines  int
+
+	// rendered lines of grid layouts, dropped when the selection changes
+	gridLines []string
+
+	// plain text of headings and rows, built when searched
+	plainHeadings []string
+	plainRows     []string
+}
+
+// Drop cached layout. Called when rows are added, removed, folded or
+// changed in a way that affects their width
+func (t *Model) Invalidate() {
+	t.cache.valid = false
+}
+
+func (t *Model) cached() *cache {
+	c := &t.cache
+	if c.valid && c.rowCount == len(t.Rows) && c.maxWidth == t.MaxWidth &&
+		c.sepWidth == t.SepWidth && c.wrap == t.Wrap && c.grid == t.Grid &&
+		c.columns == t.Columns {
+		return c
+	}
+
+	*c = cache{
+		valid:    true,
+		rowCount: len(t.Rows),
+		maxWidth: t.MaxWidth,
+		sepWidth: t.SepWidth,
+		wrap:     t.Wrap,
+		grid:     t.Grid,
+		columns:  t.Columns,
+		visible:  make([]*Row, 0, len(t.Rows)),
+	}
+
+	for _, r := range t.Rows {
+		if r == nil || r.Hidden {
+			continue
+		}
+		// copies of the selected row are not selected
+		r.IsSelected = r == t.selected
+
+		if !r.empty() {
+			c.rows = append(c.rows, r)
+			c.index = append(c.index, len(c.visible))
+		}
+		c.visible = append(c.visible, r)
+	}
+
+	// grid layouts are computed when rendered
+	if t.Grid {
+		return c
+	}
+
+	c.layout = newLayout(c.rows, t.SepWidth, (*Row).displayCells)
+	t.grid = grid{}
+	if t.Wrap && t.MaxWidth > 0 {
+		c.layout.wrap(c.rows, t.MaxWidth)
+		t.grid = grid{cells: make([]cell, len(c.visible)), colX: []int{0}, colRows: make([][]int, 1)}
+	}
+
+	c.starts = make([]int, len(c.rows))
+	for i, r := range c.rows {
+		c.starts[i] = c.lines
+		if t.grid.cells == nil {
+			c.lines++
+			continue
+		}
+
+		t.grid.cells[c.index[i]] = cell{line: c.lines}
+		for range r.height(c.layout) {
+			t.grid.colRows[0] = append(t.grid.colRows[0], c.index[i])
+			c.lines++
+		}
+	}
+	return c
+}
+
+// Rendered lines of the grid layout
+func (t *Model) cachedGrid() []string {
+	c := t.cached()
+	if c.gridLines == nil {
+		var res string
+		res, t.grid = t.renderGrid(c.visible, true, t.Columns, t.MaxWidth)
+		c.gridLines = []string{}
+		if res != "" {
+			c.gridLines = strings.Split(strings.TrimSuffix(res, "\n"), "\n")
+		}
+	}
+	return c.gridLines
+}
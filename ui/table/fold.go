@@ -0,0 +1,78 @@
+package table
+
+// Rows that are not hidden in a folded section
+func (t *Model) VisibleRows() []*Row {
+	return t.cached().visible
+}
+
+// Index of row among visible rows, -1 if absent or hidden
+func (t *Model) IndexOf(row *Row) int {
+	for i, r := range t.VisibleRows() {
+		if r == row {
+			return i
+		}
+	}
+	return -1
+}
+
+// Heading of the section containing visible row i
+func (t *Model) HeadingAt(i int) *Row {
+	rows := t.VisibleRows()
+	if i < 0 || i >= len(rows) {
+		return nil
+	}
+
+	for ; i >= 0; i-- {
+		if rows[i].IsHeading {
+			return rows[i]
+		}
+	}
+	return nil
+}
+
+// Fold or unfold the section under heading
+func (t *Model) SetFolded(heading *Row, folded bool) {
+	for i, r := range t.Rows {
+		if r == heading {
+			t.setFolded(i, folded)
+			return
+		}
+	}
+}
+
+// Fold or unfold all sections
+func (t *Model) FoldAll(folded bool) {
+	for i, r := range t.Rows {
+		if r != nil && r.IsHeading {
+			t.setFolded(i, folded)
+		}
+	}
+}
+
+func (t *Model) setFolded(i int, folded bool) {
+	heading := t.Rows[i]
+	if !heading.IsHeading || heading.Folded == folded {
+		return
+	}
+
+	heading.Folded = folded
+	heading.hiddenRows = 0
+	t.Invalidate()
+
+	for _, r := range t.Rows[i+1:] {
+		if r == nil {
+			continue
+		}
+		if r.IsHeading {
+			break
+		}
+
+		r.Hidden = folded
+		if folded {
+			heading.hiddenRows++
+			t.LineCount -= 1
+		} else {
+			t.LineCount += 1
+		}
+	}
+}
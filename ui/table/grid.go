@@ -0,0 +1,257 @@
+package table
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/charmbracelet/x/ansi"
+)
+
+// position of a visible row in the grid
+type cell struct {
+	col  int
+	line int
+}
+
+// layout of the last rendered grid
+type grid struct {
+	cells []cell
+	// starting x of each column
+	colX []int
+	// visible row index at each line of each column
+	colRows [][]int
+}
+
+// Split visible rows into sections. A section starts at each heading, or
+// where the heading of consecutive rows changes
+func sections(rows []*Row) [][]*Row {
+	var (
+		res  [][]*Row
+		prev *Row
+	)
+	for _, r := range rows {
+		if r.String() == "" {
+			continue
+		}
+		if r.IsHeading || prev == nil || (!prev.IsHeading && prev.Heading != r.Heading) {
+			res = append(res, nil)
+		}
+		res[len(res)-1] = append(res[len(res)-1], r)
+		prev = r
+	}
+	return res
+}
+
+// Distribute sections over n columns, keeping column heights balanced
+func distribute(secs [][]*Row, n int) [][]*Row {
+	var total int
+	for _, s := range secs {
+		total += len(s)
+	}
+	target := (total + n - 1) / n
+
+	var (
+		cols [][]*Row
+		cur  []*Row
+	)
+	for _, s := range secs {
+		if len(cur) > 0 && len(cur)+len(s) > target && len(cols) < n-1 {
+			cols = append(cols, cur)
+			cur = nil
+		}
+		cur = append(cur, s...)
+	}
+	return append(cols, cur)
+}
+
+// Align rows into lines, styled or plain
+func (t *Model) align(rows []*Row, styled bool) []string {
+	res := make([]string, len(rows))
+	if styled {
+		l := newLayout(rows, t.SepWidth, (*Row).displayCells)
+		if t.Wrap && !t.Grid && t.MaxWidth > 0 {
+			l.wrap(rows, t.MaxWidth)
+		}
+		for i, r := range rows {
+			res[i] = r.Render(l)
+		}
+		return res
+	}
+
+	l := newLayout(rows, t.SepWidth, (*Row).cells)
+	for i, r := range rows {
+		res[i] = r.align(l)
+	}
+	return res
+}
+
+// Number of columns of at most width that fit sections side by side
+func (t *Model) fitColumns(secs [][]*Row, styled bool, width int) int {
+	if width <= 0 {
+		return 1
+	}
+
+	var widest int
+	for _, s := range secs {
+		for _, line := range t.align(s, styled) {
+			widest = max(widest, lipgloss.Width(line))
+		}
+	}
+
+	gap := max(2, t.SepWidth)
+	return max(1, (width+gap)/(widest+gap))
+}
+
+// Flow sections into columns, each aligned on its own, and join them side by
+// side. Columns are fitted into width unless a number of columns is given
+func (t *Model) renderGrid(rows []*Row, styled bool, columns, width int) (string, grid) {
+	secs := sections(rows)
+	if len(secs) == 0 {
+		return "", grid{}
+	}
+
+	index := make(map[*Row]int, len(rows))
+	for i, r := range rows {
+		index[r] = i
+	}
+
+	if columns <= 0 {
+		columns = t.fitColumns(secs, styled, width)
+	}
+	columns = max(1, min(columns, len(secs)))
+
+	cols := distribute(secs, columns)
+	layout := grid{
+		cells:   make([]cell, len(rows)),
+		colX:    make([]int, len(cols)),
+		colRows: make([][]int, len(cols)),
+	}
+
+	var (
+		lines  [][]string
+		widths []int
+		height int
+	)
+	for c, colRows := range cols {
+		aligned := t.align(colRows, styled)
+
+		var w int
+		for _, line := range aligned {
+			w = max(w, lipgloss.Width(line))
+		}
+
+		for l, r := range colRows {
+			layout.cells[index[r]] = cell{col: c, line: l}
+			layout.colRows[c] = append(layout.colRows[c], index[r])
+		}
+
+		lines = append(lines, aligned)
+		widths = append(widths, w)
+		height = max(height, len(aligned))
+	}
+
+	gap := max(2, t.SepWidth)
+	for c := 1; c < len(cols); c++ {
+		layout.colX[c] = layout.colX[c-1] + widths[c-1] + gap
+	}
+
+	var sb strings.Builder
+	for l := range height {
+		var line strings.Builder
+		for c := range cols {
+			var s string
+			if l < len(lines[c]) {
+				s = lines[c][l]
+			}
+			line.WriteString(s)
+
+			// pad all but the last column
+			if c < len(cols)-1 {
+				line.WriteString(strings.Repeat(" ", widths[c]-lipgloss.Width(s)+gap))
+			}
+		}
+
+		row := strings.TrimRight(line.String(), " ")
+		if width > 0 && lipgloss.Width(row) > width {
+			row = ansi.Truncate(row, width, "...")
+		}
+		fmt.Fprintln(&sb, row)
+	}
+	return sb.String(), layout
+}
+
+// Build the layout of list rows, grid layouts are built when rendered
+func (t *Model) layout() {
+	if !t.Grid {
+		t.cached()
+	}
+}
+
+// Column and first line of visible row i in the rendered layout
+func (t *Model) Cell(i int) (col, line int) {
+	t.layout()
+
+	if len(t.grid.cells) == 0 {
+		return 0, i
+	}
+	i = max(0, min(i, len(t.grid.cells)-1))
+	c := t.grid.cells[i]
+	return c.col, c.line
+}
+
+// Visible row at line of column col. Lines past the end of a column return
+// its last row
+func (t *Model) RowAt(col, line int) int {
+	t.layout()
+
+	if len(t.grid.colRows) == 0 {
+		return line
+	}
+
+	rows := t.grid.colRows[max(0, min(col, len(t.grid.colRows)-1))]
+	if len(rows) == 0 {
+		return line
+	}
+	return rows[max(0, min(line, len(rows)-1))]
+}
+
+// Number of lines of visible row i in the rendered layout
+func (t *Model) Lines(i int) int {
+	t.layout()
+
+	col, line := t.Cell(i)
+	if len(t.grid.colRows) == 0 {
+		return 1
+	}
+
+	rows := t.grid.colRows[col]
+	n := 0
+	for l := line; l < len(rows) && rows[l] == rows[line]; l++ {
+		n++
+	}
+	return max(1, n)
+}
+
+// Column at x of the rendered layout
+func (t *Model) ColumnAt(x int) int {
+	col := 0
+	for c, cx := range t.grid.colX {
+		if x >= cx {
+			col = c
+		}
+	}
+	return col
+}
+
+// Aligned but unstyled rows flowed into columns
+func (t *Model) GetAlignedGrid(columns, width int) string {
+	var rows []*Row
+	for _, r := range t.Rows {
+		if r != nil {
+			rows = append(rows, r)
+		}
+	}
+	res, _ := t.renderGrid(rows, false, columns, width)
+	return strings.TrimSuffix(res, "\n")
+}
@@ -2,28 +2,74 @@ package table
 
 import (
 	"fmt"
+	"strings"
 
 	"github.com/charmbracelet/lipgloss"
 )
 
+// Fields of a row that can be shown as columns
+const (
+	FieldName   = "name"
+	FieldKey    = "key"
+	FieldPrefix = "prefix"
+	FieldMode   = "mode"
+	FieldTags   = "tags"
+	FieldNotes  = "notes"
+	FieldApp    = "app"
+)
+
+type Align int
+
+const (
+	AlignLeft Align = iota
+	AlignRight
+	AlignCenter
+)
+
+type Column struct {
+	Field string
+	Align Align
+	// longer values are truncated, 0 for no limit
+	MaxWidth int
+	Style    lipgloss.Style
+}
+
+// Columns shown when none are set
+var DefaultColumns = []Column{{Field: FieldName}, {Field: FieldKey}}
+
 type Row struct {
 	Text      string
 	Key       string
 	Prefix    string
 	PrefixSep string
+	Mode      string
+	Tags      []string
+	Notes     string
 
 	// default false unless prefix defined
 	ShowPrefix bool
 	// only used to show row's corresponding heading during filtering
 	Heading string
 
+	// fields shown, in order
+	Columns []Column
+
+	// byte offsets of String matched by search
 	MatchedIndex []int
 	Styles       RowStyles
 
 	IsHeading  bool
 	IsSelected bool
 	IsFiltered bool
-	Reversed   bool
+
+	// copy of a row shown under the Favourites heading
+	Virtual bool
+
+	// heading whose section is collapsed
+	Folded     bool
+	hiddenRows int
+	// row in a collapsed section
+	Hidden bool
 }
 
 type RowStyles struct {
@@ -32,6 +78,8 @@ type RowStyles struct {
 	Selected        lipgloss.Style
 	SelectedHeading lipgloss.Style
 	Filtered        lipgloss.Style
+	Key             lipgloss.Style
+	Prefix          lipgloss.Style
 }
 
 func NewHeading(text string) *Row {
@@ -56,71 +104,299 @@ func EmptyRow() *Row {
 	return &Row{}
 }
 
-func (r *Row) String() string {
-	if r.Text == "" && r.Key == "" {
-		return ""
+// Copies are never folded, hidden or selected
+func (r *Row) Copy() Row {
+	c := *r
+	c.Folded = false
+	c.hiddenRows = 0
+	c.Hidden = false
+	c.IsSelected = false
+	return c
+}
+
+func (r *Row) columns() []Column {
+	if len(r.Columns) == 0 {
+		return DefaultColumns
 	}
+	return r.Columns
+}
 
+func (r *Row) hasColumn(field string) bool {
+	for _, c := range r.columns() {
+		if c.Field == field {
+			return true
+		}
+	}
+	return false
+}
+
+// Value of a field. The prefix is part of the key unless it has its own column
+func (r *Row) Field(name string) string {
+	switch name {
+	case FieldName:
+		return r.Text
+	case FieldKey:
+		if r.ShowPrefix && !r.hasColumn(FieldPrefix) {
+			return fmt.Sprintf("%s %s %s", r.Prefix, r.PrefixSep, r.Key)
+		}
+		return r.Key
+	case FieldPrefix:
+		if r.ShowPrefix {
+			return r.Prefix
+		}
+	case FieldMode:
+		return r.Mode
+	case FieldTags:
+		return strings.Join(r.Tags, ", ")
+	case FieldNotes:
+		return r.Notes
+	case FieldApp:
+		return r.Heading
+	}
+	return ""
+}
+
+// Text of each column. Headings only fill the first column
+func (r *Row) cells() []string {
 	if r.IsHeading {
-		return fmt.Sprintf("%s\t ", r.Text)
+		return []string{r.Text, " "}
+	}
+
+	cols := r.columns()
+	res := make([]string, len(cols))
+	for i, c := range cols {
+		res[i] = r.Field(c.Field)
 	}
+	return res
+}
 
-	if r.Reversed {
-		return r.ReverseString()
+// Displayed text of each column. Folded headings show the number of hidden rows
+func (r *Row) displayCells() []string {
+	if r.IsHeading && r.Folded {
+		return []string{fmt.Sprintf("%s (%d)", r.Text, r.hiddenRows), " "}
 	}
+	return r.cells()
+}
 
-	if !r.ShowPrefix {
-		return fmt.Sprintf("%s\t%s", r.Text, r.Key)
+// Column of cell i
+func (r *Row) column(i int) Column {
+	cols := r.columns()
+	if r.IsHeading || i >= len(cols) {
+		return Column{}
 	}
+	return cols[i]
+}
 
-	key := fmt.Sprintf("%s %s %s", r.Prefix, r.PrefixSep, r.Key)
-	return fmt.Sprintf("%s\t%s", r.Text, key)
+// Rows without name and key are not shown
+func (r *Row) empty() bool {
+	return r.Text == "" && r.Key == ""
 }
 
-func (r *Row) ReverseString() string {
-	if !r.ShowPrefix {
-		return fmt.Sprintf("%s\t%s", r.Key, r.Text)
+// Columns separated by tabs
+func (r *Row) String() string {
+	if r.empty() {
+		return ""
 	}
+	return strings.Join(r.cells(), "\t")
+}
 
-	key := fmt.Sprintf("%s %s %s", r.Prefix, r.PrefixSep, r.Key)
-	return fmt.Sprintf("%s\t%s", key, r.Text)
+func (r *Row) display() string {
+	return strings.Join(r.displayCells(), "\t")
+}
+
+// Aligned but unstyled text
+func (r *Row) align(l Layout) string {
+	var sb strings.Builder
+	cells := r.cells()
+	for i, c := range cells {
+		col := r.column(i)
+		text, _, tail := truncateCell(c, col.MaxWidth)
+		before, after := l.pad(i, len(cells), lipgloss.Width(text+tail), col.Align)
+
+		sb.WriteString(strings.Repeat(" ", before))
+		sb.WriteString(text + tail)
+		sb.WriteString(strings.Repeat(" ", after))
+	}
+	return sb.String()
 }
 
-func (r *Row) Render() string {
+// Number of lines of the row once its cells are wrapped
+func (r *Row) height(l Layout) int {
+	h := 1
+	for i, c := range r.displayCells() {
+		if _, _, tail := truncateCell(c, r.column(i).MaxWidth); tail == "" {
+			h = max(h, len(wrapCell(c, l.limit(i))))
+		}
+	}
+	return h
+}
+
+// Aligned and styled text. Cells of wrapped columns may span several lines
+func (r *Row) Render(l Layout) string {
 	s := r.Styles
 
-	if r.IsSelected {
-		if r.IsFiltered {
-			// Inline to remove margins, paddings and borders from styledrunes
-			unmatched := s.Selected.Inline(true)
-			matched := s.Filtered.Inherit(unmatched)
-			str := lipgloss.StyleRunes(r.String(), r.MatchedIndex, matched, unmatched)
+	var outer lipgloss.Style
+	switch {
+	case r.IsSelected && r.IsHeading:
+		outer = s.SelectedHeading
+	case r.IsSelected:
+		outer = s.Selected
+	case r.IsHeading:
+		outer = s.Heading
+	default:
+		outer = s.Normal
+	}
+
+	// Inline to remove margins, paddings and borders from segments
+	base := outer.Inline(true)
+
+	isMatched := make(map[int]bool)
+	if r.IsFiltered {
+		for _, i := range r.MatchedIndex {
+			isMatched[i] = true
+		}
+	}
+
+	type part struct {
+		text  string
+		col   Column
+		gs    []grapheme
+		segs  []segment
+		lines [][2]int
+		tail  string
+		// byte offset of the cell in String, which matched indexes refer to
+		offset int
+	}
+
+	var (
+		cells  = r.displayCells()
+		parts  = make([]part, len(cells))
+		height = 1
+		offset int
+	)
+	for i, c := range cells {
+		col := r.column(i)
+		_, keep, tail := truncateCell(c, col.MaxWidth)
+		lines := [][2]int{{0, keep}}
+		if tail == "" {
+			lines = wrapCell(c, l.limit(i))
+		}
+
+		parts[i] = part{c, col, graphemes(c), r.segments(i, c, col, base), lines, tail, offset}
+		height = max(height, len(lines))
+
+		// cells are separated by a tab
+		offset += len(c) + 1
+	}
+
+	res := make([]string, height)
+	for k := range height {
+		var sb strings.Builder
+		for i, p := range parts {
+			var (
+				cell strings.Builder
+				w    int
+			)
+			if k < len(p.lines) {
+				from, to := p.lines[k][0], p.lines[k][1]
+				if k > 0 {
+					cell.WriteString(strings.Repeat(" ", wrapIndent))
+					w += wrapIndent
+				}
+				renderSegments(&cell, p.segs, p.gs[from:to], p.offset, isMatched, s.Filtered)
+				w += rangeWidth(p.gs, from, to)
 
-			if r.IsHeading {
-				return s.SelectedHeading.Render(str)
+				if p.tail != "" {
+					cell.WriteString(p.segs[len(p.segs)-1].style.Render(p.tail))
+					w += lipgloss.Width(p.tail)
+				}
 			}
-			return s.Selected.Render(str)
+
+			before, after := l.pad(i, len(cells), w, p.col.Align)
+			sb.WriteString(strings.Repeat(" ", before))
+			sb.WriteString(cell.String())
+			sb.WriteString(strings.Repeat(" ", after))
 		}
+		res[k] = outer.Render(sb.String())
+	}
+	return strings.Join(res, "\n")
+}
+
+type segment struct {
+	text  string
+	style lipgloss.Style
+}
 
-		if r.IsHeading {
-			return s.SelectedHeading.Render(r.String())
+// Split cell i into separately styled parts
+func (r *Row) segments(i int, text string, col Column, base lipgloss.Style) []segment {
+	style := col.Style.Inherit(base).Inline(true)
+	if r.IsHeading || r.IsSelected {
+		return []segment{{text: text, style: base}}
+	}
+
+	key := col.Style.Inherit(r.Styles.Key).Inherit(base).Inline(true)
+	prefix := col.Style.Inherit(r.Styles.Prefix).Inherit(base).Inline(true)
+
+	switch col.Field {
+	case FieldKey:
+		if r.ShowPrefix && !r.hasColumn(FieldPrefix) {
+			p := fmt.Sprintf("%s %s ", r.Prefix, r.PrefixSep)
+			return []segment{{text: p, style: prefix}, {text: r.Key, style: key}}
 		}
-		return s.Selected.Render(r.String())
+		return []segment{{text: text, style: key}}
+	case FieldPrefix:
+		return []segment{{text: text, style: prefix}}
 	}
+	return []segment{{text: text, style: style}}
+}
 
-	if r.IsFiltered {
-		unmatched := s.Normal.Inline(true)
-		matched := s.Filtered.Inherit(unmatched)
-		str := lipgloss.StyleRunes(r.String(), r.MatchedIndex, matched, unmatched)
+// Render graphemes of segments, highlighting graphemes with a byte at a
+// matched index. Indexes start at offset
+func renderSegments(sb *strings.Builder, segs []segment, gs []grapheme, offset int, isMatched map[int]bool, filtered lipgloss.Style) {
+	var (
+		text       strings.Builder
+		seg        int
+		segStart   int
+		runMatched bool
+		run        strings.Builder
+	)
+	for _, sg := range segs {
+		text.WriteString(sg.text)
+	}
+	cell := text.String()
 
-		if r.IsHeading {
-			return s.Heading.Render(str)
+	flush := func() {
+		if run.Len() == 0 {
+			return
+		}
+		style := segs[seg].style
+		if runMatched {
+			style = filtered.Inherit(style)
 		}
-		return s.Normal.Render(str)
+		sb.WriteString(style.Render(run.String()))
+		run.Reset()
 	}
 
-	if r.IsHeading {
-		return s.Heading.Render(r.String())
+	for _, g := range gs {
+		// segment of the grapheme
+		for seg < len(segs)-1 && g.start >= segStart+len(segs[seg].text) {
+			flush()
+			segStart += len(segs[seg].text)
+			seg++
+		}
+
+		matched := false
+		for b := range g.size {
+			if isMatched[offset+g.start+b] {
+				matched = true
+				break
+			}
+		}
+		if run.Len() > 0 && matched != runMatched {
+			flush()
+		}
+		runMatched = matched
+		run.WriteString(cell[g.start : g.start+g.size])
 	}
-	return s.Normal.Render(r.String())
+	flush()
 }
@@ -2,10 +2,11 @@ package table
 
 import (
 	"fmt"
+	"sort"
 	"strings"
 
-	"github.com/juju/ansiterm/tabwriter"
-	"github.com/muesli/reflow/truncate"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/charmbracelet/x/ansi"
 )
 
 type Model struct {
@@ -13,6 +14,18 @@ type Model struct {
 	LineCount int
 	SepWidth  int
 	MaxWidth  int // prevents line wrapping
+
+	// wrap name and key columns to fit MaxWidth instead of truncating
+	Wrap bool
+
+	// flow sections into columns
+	Grid bool
+	// number of grid columns, 0 fits as many as MaxWidth allows
+	Columns int
+	grid    grid
+
+	cache    cache
+	selected *Row
 }
 
 func New(rows []*Row) *Model {
@@ -40,21 +53,45 @@ func (t *Model) Empty() bool {
 }
 
 func (t *Model) AppendRow(row *Row) {
+	t.Invalidate()
 	t.Rows = append(t.Rows, row)
 	t.LineCount += 1
 }
 
 func (t *Model) AppendRows(rows ...*Row) {
+	t.Invalidate()
 	t.Rows = append(t.Rows, rows...)
 	t.LineCount += len(rows)
 }
 
+func (t *Model) Prepend(rows ...*Row) {
+	t.Invalidate()
+	t.Rows = append(rows, t.Rows...)
+	t.LineCount += len(rows)
+}
+
+// Remove all virtual rows
+func (t *Model) RemoveVirtual() {
+	t.Invalidate()
+	var rows []*Row
+	for _, r := range t.Rows {
+		if r != nil && r.Virtual {
+			t.LineCount -= 1
+			continue
+		}
+		rows = append(rows, r)
+	}
+	t.Rows = rows
+}
+
 func (t *Model) Join(table *Model) {
+	t.Invalidate()
 	t.Rows = append(t.Rows, table.Rows...)
 	t.LineCount += table.LineCount
 }
 
 func (t *Model) Reset() {
+	t.Invalidate()
 	t.Rows = nil
 	t.LineCount = 0
 }
@@ -62,62 +99,132 @@ func (t *Model) Reset() {
 // Align and style rows
 func (t *Model) Render() string {
 	var sb strings.Builder
-	tw := tabwriter.NewWriter(&sb, 8, 4, t.SepWidth, ' ', 0)
+	for _, line := range t.RenderLines(0, t.TotalLines()) {
+		fmt.Fprintln(&sb, line)
+	}
+	return sb.String()
+}
 
-	for _, row := range t.Rows {
-		if row != nil && row.String() != "" {
-			fmt.Fprintln(tw, row.Render())
+// Align and style the rendered lines from to to. Only rows on those lines are
+// rendered
+func (t *Model) RenderLines(from, to int) []string {
+	if t.Grid {
+		lines := t.cachedGrid()
+		from, to = max(0, from), min(to, len(lines))
+		if from >= to {
+			return nil
 		}
+		return lines[from:to]
+	}
+
+	c := t.cached()
+	from, to = max(0, from), min(to, c.lines)
+	if from >= to {
+		return nil
 	}
-	tw.Flush()
 
-	if sb.String() != "" {
-		sl := strings.Split(strings.TrimSuffix(sb.String(), "\n"), "\n")
-		sb.Reset()
+	// first row with a line in view
+	first := max(0, sort.Search(len(c.starts), func(i int) bool {
+		return c.starts[i] > from
+	})-1)
 
-		// Unable to truncate while aligning due to nature of tabwriter
-		for _, row := range sl {
-			if t.MaxWidth > 0 {
-				fmt.Fprintln(&sb, truncate.StringWithTail(row, uint(t.MaxWidth), "..."))
-			} else {
-				fmt.Fprintln(&sb, row)
+	var res []string
+	for i := first; i < len(c.rows) && c.starts[i] < to; i++ {
+		for _, line := range strings.Split(c.rows[i].Render(c.layout), "\n") {
+			if t.MaxWidth > 0 && lipgloss.Width(line) > t.MaxWidth {
+				line = ansi.Truncate(line, t.MaxWidth, "...")
 			}
+			res = append(res, line)
 		}
 	}
-	return sb.String()
+
+	skip := from - c.starts[first]
+	return res[skip:min(len(res), skip+to-from)]
+}
+
+// Number of rendered lines
+func (t *Model) TotalLines() int {
+	if t.Grid {
+		return len(t.cachedGrid())
+	}
+	return t.cached().lines
+}
+
+// Select visible row i, unselecting the previous one
+func (t *Model) Select(i int) *Row {
+	var row *Row
+	if rows := t.VisibleRows(); i >= 0 && i < len(rows) {
+		row = rows[i]
+	}
+	if row == t.selected {
+		return row
+	}
+
+	if t.selected != nil {
+		t.selected.IsSelected = false
+	}
+	t.selected = row
+	if row != nil {
+		row.IsSelected = true
+	}
+	// rendered grid shows the previous selection
+	t.cache.gridLines = nil
+	return row
 }
 
 // Helper functions for retrieving specific rows in a table
 // Aligned but unstyled rows
 func (t *Model) GetAlignedRows() string {
-	var sb strings.Builder
-	tw := tabwriter.NewWriter(&sb, 8, 4, t.SepWidth, ' ', 0)
-
+	var rows []*Row
 	for _, row := range t.Rows {
 		if row != nil && row.String() != "" {
-			fmt.Fprintln(tw, row.String())
+			rows = append(rows, row)
 		}
 	}
-
-	tw.Flush()
-	return strings.TrimSuffix(sb.String(), "\n")
+	return strings.Join(t.align(rows, false), "\n")
 }
 
+// Plain text of headings, as matched by search
 func (t *Model) GetPlainHeadings() []string {
-	var res []string
+	c := t.cached()
+	if c.plainHeadings == nil {
+		c.plainHeadings = plain(t.GetHeadings())
+	}
+	return c.plainHeadings
+}
+
+// Plain text of rows, as matched by search
+func (t *Model) GetPlainRowsWithoutHeadings() []string {
+	c := t.cached()
+	if c.plainRows == nil {
+		c.plainRows = plain(t.GetRowsWithoutHeadings())
+	}
+	return c.plainRows
+}
+
+func plain(rows []*Row) []string {
+	res := make([]string, len(rows))
+	for i, r := range rows {
+		res[i] = r.String()
+	}
+	return res
+}
+
+func (t *Model) GetHeadings() []*Row {
+	var res []*Row
 	for _, r := range t.Rows {
-		if r.IsHeading {
-			res = append(res, r.String())
+		if r.IsHeading && !r.Virtual {
+			res = append(res, r)
 		}
 	}
 	return res
 }
 
-func (t *Model) GetPlainRowsWithoutHeadings() []string {
-	var res []string
+func (t *Model) GetRowsWithoutHeadings() []*Row {
+	res := make([]*Row, 0, len(t.Rows))
 	for _, r := range t.Rows {
-		if !r.IsHeading {
-			res = append(res, r.String())
+		if !r.IsHeading && !r.Virtual {
+			res = append(res, r)
 		}
 	}
 	return res
@@ -125,20 +232,16 @@ func (t *Model) GetPlainRowsWithoutHeadings() []string {
 
 func (t *Model) GetCopyOfHeadings() []Row {
 	var res []Row
-	for _, r := range t.Rows {
-		if r.IsHeading {
-			res = append(res, *r)
-		}
+	for _, r := range t.GetHeadings() {
+		res = append(res, r.Copy())
 	}
 	return res
 }
 
 func (t *Model) GetCopyOfRowsWithoutHeadings() []Row {
 	var res []Row
-	for _, r := range t.Rows {
-		if !r.IsHeading {
-			res = append(res, *r)
-		}
+	for _, r := range t.GetRowsWithoutHeadings() {
+		res = append(res, r.Copy())
 	}
 	return res
 }
@@ -146,7 +249,7 @@ func (t *Model) GetCopyOfRowsWithoutHeadings() []Row {
 func (t *Model) GetAllRowsofHeading(heading string) []*Row {
 	var res []*Row
 	for _, r := range t.Rows {
-		if !r.IsHeading && r.Heading == heading {
+		if !r.IsHeading && !r.Virtual && r.Heading == heading {
 			res = append(res, r)
 		}
 	}
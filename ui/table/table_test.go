@@ -1,9 +1,18 @@
 package table
 
 import (
+	"flag"
+	"os"
+	"path/filepath"
+	"strings"
 	"testing"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/sahilm/fuzzy"
 )
 
+var update = flag.Bool("update", false, "update golden files")
+
 var (
 	testRows = []*Row{
 		NewHeading("heading"),
@@ -69,6 +78,336 @@ func TestReset(t *testing.T) {
 	assertEqual(t, tt.Render(), "")
 }
 
+func TestFold(t *testing.T) {
+	newTable := func() *Model {
+		return New([]*Row{
+			NewHeading("foo"),
+			NewRow("a", "1", "", "foo"),
+			NewRow("b", "2", "", "foo"),
+			NewHeading("bar"),
+			NewRow("c", "3", "", "bar"),
+		})
+	}
+
+	t.Run("fold section", func(t *testing.T) {
+		tt := newTable()
+		tt.SetFolded(tt.HeadingAt(2), true)
+
+		assertEqual(t, tt.LineCount, 3)
+		assertEqual(t, len(tt.VisibleRows()), 3)
+		assertEqual(t, tt.VisibleRows()[1].Text, "bar")
+		assertEqual(t, tt.IndexOf(tt.Rows[2]), -1)
+		assertEqual(t, tt.Rows[0].display(), "foo (2)\t ")
+		assertEqual(t, tt.GetAlignedRows(), New(newTable().Rows).GetAlignedRows())
+	})
+
+	t.Run("fold all", func(t *testing.T) {
+		tt := newTable()
+		tt.FoldAll(true)
+		assertEqual(t, tt.LineCount, 2)
+		assertEqual(t, tt.HeadingAt(1).Text, "bar")
+
+		tt.FoldAll(false)
+		assertEqual(t, tt.LineCount, 5)
+		assertEqual(t, tt.Render(), newTable().Render())
+	})
+
+	t.Run("copies are unfolded", func(t *testing.T) {
+		tt := newTable()
+		tt.FoldAll(true)
+
+		assertEqual(t, tt.GetCopyOfHeadings()[0].Folded, false)
+		assertEqual(t, tt.GetCopyOfRowsWithoutHeadings()[0].Hidden, false)
+	})
+}
+
+func TestGrid(t *testing.T) {
+	newGrid := func() *Model {
+		var rows []*Row
+		for _, h := range []string{"a", "b", "c"} {
+			rows = append(rows, NewHeading(h))
+			rows = append(rows, &Row{Text: h + "1", Key: "x", Heading: h})
+			rows = append(rows, &Row{Text: h + "2", Key: "y", Heading: h})
+		}
+		tt := New(rows)
+		tt.SepWidth = 2
+		tt.Grid = true
+		return tt
+	}
+
+	t.Run("fixed columns", func(t *testing.T) {
+		want := "a          b\n" +
+			"a1      x  b1      x\n" +
+			"a2      y  b2      y\n" +
+			"           c\n" +
+			"           c1      x\n" +
+			"           c2      y"
+
+		tt := newGrid()
+		assertEqual(t, tt.GetAlignedGrid(2, 0), want)
+	})
+
+	t.Run("fit width", func(t *testing.T) {
+		tt := newGrid()
+		assertEqual(t, tt.GetAlignedGrid(0, 40), "a          b          c\n"+
+			"a1      x  b1      x  c1      x\n"+
+			"a2      y  b2      y  c2      y")
+		assertEqual(t, strings.Count(tt.GetAlignedGrid(0, 10), "\n"), 8)
+	})
+
+	t.Run("cells", func(t *testing.T) {
+		tt := newGrid()
+		tt.Columns = 3
+		tt.Render()
+
+		col, line := tt.Cell(4)
+		assertEqual(t, col, 1)
+		assertEqual(t, line, 1)
+
+		assertEqual(t, tt.RowAt(2, 1), 7)
+		assertEqual(t, tt.RowAt(2, 10), 8)
+		assertEqual(t, tt.ColumnAt(0), 0)
+	})
+
+	t.Run("cached", func(t *testing.T) {
+		tt := newGrid()
+		tt.Columns = 3
+		want := tt.Render()
+		assertEqual(t, tt.TotalLines(), 3)
+
+		// kept until the table is invalidated
+		tt.Rows[1].Text = "changed"
+		assertEqual(t, tt.Render(), want)
+		tt.Invalidate()
+		assertEqual(t, strings.Contains(tt.Render(), "changed"), true)
+
+		tt.Columns = 1
+		assertEqual(t, tt.TotalLines(), 9)
+
+		// selection is rendered again
+		tt.Select(1)
+		assertEqual(t, tt.cache.gridLines == nil, true)
+		tt.RenderLines(0, 9)
+		tt.Select(1)
+		assertEqual(t, tt.cache.gridLines == nil, false)
+	})
+}
+
+func TestColumns(t *testing.T) {
+	newTable := func(columns ...Column) *Model {
+		rows := []*Row{
+			NewHeading("app"),
+			NewRow("foo", "bar", "ctrl", "app"),
+			{Text: "bazqux", Key: "f", Mode: "normal", Tags: []string{"a", "b"}, Heading: "app"},
+		}
+		for _, r := range rows {
+			r.PrefixSep = "+"
+			r.Columns = columns
+		}
+		tt := New(rows)
+		tt.SepWidth = 2
+		return tt
+	}
+
+	t.Run("default", func(t *testing.T) {
+		tt := newTable()
+		assertEqual(t, tt.GetAlignedRows(), "app      \n"+
+			"foo     ctrl + bar\n"+
+			"bazqux  f")
+	})
+
+	t.Run("order", func(t *testing.T) {
+		tt := newTable(Column{Field: FieldKey}, Column{Field: FieldName})
+		assertEqual(t, tt.GetAlignedRows(), "app          \n"+
+			"ctrl + bar  foo\n"+
+			"f           bazqux")
+	})
+
+	t.Run("prefix column", func(t *testing.T) {
+		tt := newTable(Column{Field: FieldPrefix}, Column{Field: FieldKey}, Column{Field: FieldTags})
+		assertEqual(t, tt.GetAlignedRows(), "app      \n"+
+			"ctrl    bar     \n"+
+			"        f       a, b")
+	})
+
+	t.Run("align and max width", func(t *testing.T) {
+		tt := newTable(
+			Column{Field: FieldName, MaxWidth: 5},
+			Column{Field: FieldKey, Align: AlignRight},
+			Column{Field: FieldMode, Align: AlignCenter},
+			Column{Field: FieldApp},
+		)
+		assertEqual(t, tt.GetAlignedRows(), "app      \n"+
+			"foo     ctrl + bar          app\n"+
+			"ba...            f  normal  app")
+	})
+
+	t.Run("matched index across columns", func(t *testing.T) {
+		tt := newTable()
+		r := tt.Rows[2]
+		r.IsFiltered = true
+		r.MatchedIndex = []int{0, 7}
+		r.Styles.Filtered = r.Styles.Filtered.Bold(true)
+
+		l := newLayout(tt.Rows, tt.SepWidth, (*Row).displayCells)
+		assertEqual(t, r.Render(l), "bazqux  f")
+	})
+}
+
+func TestWrap(t *testing.T) {
+	t.Run("wrap cell", func(t *testing.T) {
+		got := wrapCell("tar -xzvf archive.tar.gz", 10)
+		want := [][2]int{{0, 9}, {10, 18}, {18, 24}}
+		if len(got) != len(want) {
+			t.Fatalf("got %v, want %v", got, want)
+		}
+		for i := range want {
+			assertEqual(t, got[i], want[i])
+		}
+	})
+
+	newTable := func() *Model {
+		tt := New([]*Row{
+			NewHeading("tar"),
+			{Text: "extract gzipped archive", Key: "tar -xzvf archive.tar.gz", Heading: "tar"},
+			{Text: "list", Key: "tar -tf a", Heading: "tar"},
+		})
+		tt.SepWidth = 2
+		tt.MaxWidth = 30
+		tt.Wrap = true
+		return tt
+	}
+
+	t.Run("render", func(t *testing.T) {
+		tt := newTable()
+		assertEqual(t, tt.Render(), "tar              \n"+
+			"extract         tar -xzvf\n"+
+			"  gzipped         archive.tar.\n"+
+			"  archive         gz\n"+
+			"list            tar -tf a\n")
+	})
+
+	t.Run("lines", func(t *testing.T) {
+		tt := newTable()
+		tt.Render()
+
+		_, line := tt.Cell(2)
+		assertEqual(t, line, 4)
+		assertEqual(t, tt.Lines(1), 3)
+		assertEqual(t, tt.Lines(2), 1)
+		assertEqual(t, tt.RowAt(0, 2), 1)
+		assertEqual(t, tt.RowAt(0, 4), 2)
+	})
+
+	t.Run("highlight wrapped", func(t *testing.T) {
+		tt := newTable()
+		r := tt.Rows[1]
+		r.IsFiltered = true
+		// z of gzipped and i of archive, on the second and third line
+		r.MatchedIndex = []int{9, 20}
+		// uppercase keeps the width of highlighted graphemes
+		r.Styles.Filtered = lipgloss.NewStyle().Transform(strings.ToUpper)
+
+		got := tt.Render()
+		lines := strings.Split(got, "\n")
+		assertEqual(t, len(lines), 6)
+		assertEqual(t, strings.Contains(lines[2], "gZipped"), true)
+		assertGolden(t, got)
+	})
+}
+
+func TestUnicode(t *testing.T) {
+	newTable := func(columns ...Column) *Model {
+		rows := []*Row{
+			NewHeading("🚀 launch"),
+			{Text: "日本語テキスト", Key: "ctrl+j", Heading: "🚀 launch"},
+			{Text: "cafe\u0301 menu", Key: "c", Heading: "🚀 launch"},
+			{Text: "family 👨‍👩‍👧", Key: "👍", Heading: "🚀 launch"},
+			{Text: "plain", Key: "p", Heading: "🚀 launch"},
+		}
+		for _, r := range rows {
+			r.Columns = columns
+		}
+		tt := New(rows)
+		tt.SepWidth = 2
+		return tt
+	}
+
+	t.Run("aligned", func(t *testing.T) {
+		assertGolden(t, newTable().GetAlignedRows())
+	})
+
+	t.Run("right aligned", func(t *testing.T) {
+		tt := newTable(Column{Field: FieldKey, Align: AlignRight}, Column{Field: FieldName})
+		assertGolden(t, tt.GetAlignedRows())
+	})
+
+	t.Run("truncated", func(t *testing.T) {
+		tt := newTable(Column{Field: FieldName, MaxWidth: 9}, Column{Field: FieldKey})
+		assertGolden(t, tt.GetAlignedRows())
+	})
+
+	t.Run("wrapped", func(t *testing.T) {
+		tt := New([]*Row{
+			{Text: "日本語のとても長い説明文です", Key: "ctrl+j"},
+			{Text: "emoji 🎉 party 🎉 time 🎉 again", Key: "e"},
+		})
+		tt.SepWidth = 2
+		tt.MaxWidth = 24
+		tt.Wrap = true
+		assertGolden(t, tt.Render())
+	})
+
+	t.Run("highlighted", func(t *testing.T) {
+		tt := newTable()
+		var rows []string
+		for _, r := range tt.Rows[1:] {
+			rows = append(rows, r.String())
+		}
+
+		// mark highlighted graphemes
+		filtered := lipgloss.NewStyle().Transform(func(s string) string {
+			return "[" + s + "]"
+		})
+		for _, m := range fuzzy.Find("本テ", rows) {
+			r := tt.Rows[m.Index+1]
+			r.IsFiltered, r.MatchedIndex = true, m.MatchedIndexes
+		}
+		for _, m := range fuzzy.Find("e\u0301m", rows) {
+			r := tt.Rows[m.Index+1]
+			r.IsFiltered, r.MatchedIndex = true, m.MatchedIndexes
+		}
+		for _, m := range fuzzy.Find("👧👍", rows) {
+			r := tt.Rows[m.Index+1]
+			r.IsFiltered, r.MatchedIndex = true, m.MatchedIndexes
+		}
+		for _, r := range tt.Rows {
+			r.Styles.Filtered = filtered
+		}
+		assertGolden(t, tt.Render())
+	})
+}
+
+// Compare got with testdata/<test name>.golden, rewriting it with -update
+func assertGolden(t *testing.T, got string) {
+	t.Helper()
+	path := filepath.Join("testdata", strings.ReplaceAll(t.Name(), "/", "_")+".golden")
+	if *update {
+		if err := os.MkdirAll("testdata", 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(got), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	want, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assertEqual(t, got, string(want))
+}
+
 func assertEqual[T comparable](t *testing.T, got, want T) {
 	if got != want {
 		t.Errorf("got %#v, want %#v", got, want)
@@ -3,25 +3,47 @@ package ui
 import (
 	"sort"
 	"strings"
+	"time"
 
 	"github.com/kencx/keyb/config"
 	"github.com/kencx/keyb/ui/list"
 	"github.com/kencx/keyb/ui/table"
 
+	"github.com/charmbracelet/bubbles/key"
 	tea "github.com/charmbracelet/bubbletea"
 )
 
+// edits are written to disk once no further edits are made for this long
+const flushDelay = time.Second
+
 type Model struct {
 	List list.Model
 	Apps *config.Apps
+
+	// editor of the keyb file, nil if it cannot be edited
+	editor *config.Editor
+	// identifies the latest edit so only its flush is run
+	flushID int
+
+	// overlay of keyb's own key bindings, shown as a keyb list
+	Help     list.Model
+	showHelp bool
+
+	config        *config.Config
+	width, height int
+
+	// window height, drawn inline below the prompt unless full screen
+	windowHeight config.Height
+	quitting     bool
 }
 
 func NewModel(a config.Apps, config *config.Config) *Model {
 
 	table := createParentTable(a, config.SortKeys)
 	return &Model{
-		List: list.New(table, config),
-		Apps: &a,
+		List:   list.New(table, config),
+		Apps:   &a,
+		config: config,
 	}
 }
 
@@ -36,11 +58,11 @@ func createParentTable(a config.Apps, sortKeys bool) *table.Model {
 		return a[i].Name < a[j].Name
 	})
 
-	parent := appToTable(a[0].Name, *a[0], sortKeys)
+	parent := appToTable(a[0].Heading(), *a[0], sortKeys)
 
 	if len(a) > 1 {
 		for _, k := range a[1:] {
-			child := appToTable(k.Name, *k, sortKeys)
+			child := appToTable(k.Heading(), *k, sortKeys)
 			parent.Join(child)
 		}
 	}
@@ -62,6 +84,9 @@ func appToTable(heading string, app config.App, sortKeys bool) *table.Model {
 	// convert Keybind to Row
 	for _, kb := range app.Keybinds {
 		row := table.NewRow(kb.Name, kb.Key, app.Prefix, heading)
+		row.Mode = kb.Mode
+		row.Tags = kb.Tags
+		row.Notes = kb.Notes
 
 		// KeyBind's ignore prefix defaults to false
 		// so user can choose to ignore prefix for a specific kb
@@ -76,7 +101,7 @@ func appToTable(heading string, app config.App, sortKeys bool) *table.Model {
 }
 
 func (m *Model) Init() tea.Cmd {
-	return nil
+	return m.List.Init()
 }
 
 func (m *Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
@@ -86,9 +111,73 @@ func (m *Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		cmds []tea.Cmd
 	)
 
+	// inline windows only take part of the terminal
+	if size, ok := msg.(tea.WindowSizeMsg); ok {
+		size.Height = m.windowHeight.Lines(size.Height)
+		msg = size
+	}
+
 	switch msg := msg.(type) {
 	case tea.WindowSizeMsg:
+		m.width, m.height = msg.Width, msg.Height
 		m.List.Resize(msg.Width, msg.Height)
+
+	case list.HelpMsg:
+		m.openHelp()
+		return m, nil
+
+	// edits requested from the help overlay are ignored
+	case list.DeleteMsg:
+		if m.showHelp {
+			return m, nil
+		}
+		return m, m.delete(msg)
+	case list.UndoMsg:
+		if m.showHelp || m.editor == nil {
+			return m, nil
+		}
+		op, err := m.editor.Undo()
+		return m, m.edited("undo ", op, err)
+	case list.RedoMsg:
+		if m.showHelp || m.editor == nil {
+			return m, nil
+		}
+		op, err := m.editor.Redo()
+		return m, m.edited("redo ", op, err)
+	case flushMsg:
+		if msg.id != m.flushID {
+			return m, nil
+		}
+		return m, m.flush()
+
+	case tea.MouseMsg:
+		// position of inline windows on the screen is unknown, so only the
+		// mouse wheel is supported
+		if m.Inline() && !tea.MouseEvent(msg).IsWheel() {
+			return m, nil
+		}
+
+	case tea.KeyMsg:
+		if m.showHelp && !m.Help.InputActive() {
+			keys := m.Help.Keys()
+			switch {
+			case msg.String() == "ctrl+c":
+				m.quitting = true
+				return m, tea.Quit
+			case key.Matches(msg, keys.Help, keys.Quit, keys.Normal):
+				m.showHelp = false
+				return m, nil
+			}
+		}
+	}
+
+	if m.showHelp {
+		m.Help, cmd = m.Help.Update(msg)
+
+		// list behind the overlay is still resized
+		if _, ok := msg.(tea.WindowSizeMsg); !ok {
+			return m, cmd
+		}
 	}
 
 	m.List, cmd = m.List.Update(msg)
@@ -97,6 +186,120 @@ func (m *Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	return m, tea.Batch(cmds...)
 }
 
+type flushMsg struct {
+	id int
+}
+
+// Edit the keyb file with editor. Without an editor, key bindings can only be
+// viewed
+func (m *Model) SetEditor(e *config.Editor) {
+	m.editor = e
+}
+
+// Remove the key binding of a row from the keyb file
+func (m *Model) delete(msg list.DeleteMsg) tea.Cmd {
+	if m.editor == nil {
+		m.List.SetMessage("keyb file cannot be edited")
+		return nil
+	}
+
+	for _, app := range *m.Apps {
+		if app.Heading() != msg.Heading {
+			continue
+		}
+		if app.Origin != "" {
+			m.List.SetMessage("cannot edit project keyb files")
+			return nil
+		}
+
+		op, err := m.editor.Remove(app.Name, msg.Name, msg.Key)
+		return m.edited("", op, err)
+	}
+	return nil
+}
+
+// Show the result of an edit, described with prefix, and schedule it to be
+// written
+func (m *Model) edited(prefix string, op config.Op, err error) tea.Cmd {
+	if err != nil {
+		m.List.SetMessage(err.Error())
+		return nil
+	}
+	m.List.SetMessage(prefix + op.String())
+	m.List.SetReload(list.ReloadPending)
+
+	m.flushID++
+	id := m.flushID
+	return tea.Batch(m.reload(), tea.Tick(flushDelay, func(time.Time) tea.Msg {
+		return flushMsg{id}
+	}))
+}
+
+// Write edits to disk, showing the keyb file as it is on disk if they cannot
+// be written
+func (m *Model) flush() tea.Cmd {
+	if err := m.Flush(); err != nil {
+		m.List.SetMessage(err.Error())
+		m.List.SetReload(list.ReloadFailed)
+		return m.reload()
+	}
+	m.List.SetReload(list.ReloadDone)
+	return nil
+}
+
+// Write edits that are not written yet
+func (m *Model) Flush() error {
+	if m.editor == nil {
+		return nil
+	}
+	return m.editor.Flush()
+}
+
+// Rebuild the list from the edited keyb file and project apps
+func (m *Model) reload() tea.Cmd {
+	apps := m.editor.Apps()
+	for _, app := range *m.Apps {
+		if app.Origin != "" {
+			apps = append(apps, app)
+		}
+	}
+	m.Apps = &apps
+	return m.List.SetTable(createParentTable(apps, m.config.SortKeys))
+}
+
+// Show key bindings of the current key map as a searchable list
+func (m *Model) openHelp() {
+	c := m.config.Copy()
+	c.Title = "keyb help"
+	c.SearchMode = false
+	c.Collapsed = false
+	c.Frecency = false
+	c.ShowHelp = false
+	c.Saved = nil
+
+	table := createParentTable(m.List.Keys().HelpApps(), false)
+	m.Help = list.New(table, c)
+	m.Help.Resize(m.width, m.height)
+	m.Help, _ = m.Help.Update(tea.WindowSizeMsg{Width: m.width, Height: m.height})
+	m.showHelp = true
+}
+
+func (m *Model) SetHeight(h config.Height) {
+	m.windowHeight = h
+}
+
+func (m *Model) Inline() bool {
+	return !m.windowHeight.FullScreen()
+}
+
 func (m *Model) View() string {
+	// clear inline window on exit
+	if m.quitting || m.List.Quitting() || m.Help.Quitting() {
+		return ""
+	}
+
+	if m.showHelp {
+		return m.Help.View()
+	}
 	return m.List.View()
 }